@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/dyedgreen/comp-phys/assignment/comply"
+	"github.com/dyedgreen/comp-phys/pkg/casino"
+	"github.com/dyedgreen/comp-phys/pkg/util"
 
 	"golang.org/x/exp/rand"
 	"gonum.org/v1/gonum/stat/distuv"
@@ -19,9 +21,12 @@ import (
 func q_a(uni distuv.Uniform) {
 	fmt.Println("Generating plot for question (a)")
 
-	numbers := make(plotter.Values, 1e5, 1e5)
-	for i := range numbers {
-		numbers[i] = uni.Rand()
+	hist, err := casino.NewHistogram(0, 1, 100)
+	if err != nil {
+		panic(err)
+	}
+	for i := 0; i < 1e5; i++ {
+		hist.Add(uni.Rand())
 	}
 
 	// Plot the generated numbers in a histogram
@@ -33,17 +38,12 @@ func q_a(uni distuv.Uniform) {
 	// Plot settings
 	p.Title.Text = "1e5 Uniform Random Numbers from [0,1]"
 
-	// Draw histogram
-	h, err := plotter.NewHist(numbers, 100)
-	if err != nil {
-		panic(err)
-	}
-	p.Add(h)
+	// Draw histogram, normalized as a density
+	p.Add(util.HistogramToPlotter(hist, true))
 
 	// Overlay PDF
 	pdf := plotter.NewFunction(func(x float64) float64 {
-		// We scale by factor, since the y-axis is not normalized
-		return 1000
+		return 1
 	})
 	pdf.Color = color.RGBA{255, 0, 0, 255}
 	p.Add(pdf)
@@ -61,9 +61,12 @@ func q_b(uni distuv.Uniform) {
 		return 2 * math.Asin(x)
 	}
 
-	numbers := make(plotter.Values, 1e5, 1e5)
-	for i := range numbers {
-		numbers[i] = y(uni.Rand())
+	hist, err := casino.NewHistogram(0, math.Pi, 100)
+	if err != nil {
+		panic(err)
+	}
+	for i := 0; i < 1e5; i++ {
+		hist.Add(y(uni.Rand()))
 	}
 
 	// Plot the generated numbers in a histogram
@@ -75,17 +78,12 @@ func q_b(uni distuv.Uniform) {
 	// Plot settings
 	p.Title.Text = "1e5 Random Numbers from p(x) = 0.5 * cos(0.5 * x)"
 
-	// Draw histogram
-	h, err := plotter.NewHist(numbers, 100)
-	if err != nil {
-		panic(err)
-	}
-	p.Add(h)
+	// Draw histogram, normalized as a density
+	p.Add(util.HistogramToPlotter(hist, true))
 
 	// Overlay PDF
 	pdf := plotter.NewFunction(func(x float64) float64 {
-		// We scale by factor, since the y-axis is not normalized
-		return 3.1e3 * 0.5 * math.Cos(0.5*x)
+		return 0.5 * math.Cos(0.5*x)
 	})
 	pdf.Color = color.RGBA{255, 0, 0, 255}
 	p.Add(pdf)
@@ -140,8 +138,15 @@ func q_c(src rand.Source) {
 	c := 1.3
 	sampler := comply.Rejection{c, target, proposal, src}
 
-	numbers := make(plotter.Values, 1e5, 1e5)
+	numbers := make([]float64, 1e5, 1e5)
 	sampler.Sample(numbers)
+	hist, err := casino.NewHistogram(0, math.Pi, 100)
+	if err != nil {
+		panic(err)
+	}
+	for _, x := range numbers {
+		hist.Add(x)
+	}
 
 	// Plot the generated numbers in a histogram
 	p, err := plot.New()
@@ -152,17 +157,12 @@ func q_c(src rand.Source) {
 	// Plot settings
 	p.Title.Text = "1e5 Random Numbers from p(x) = 2 / pi * cos^2(0.5 * x)"
 
-	// Draw histogram
-	h, err := plotter.NewHist(numbers, 100)
-	if err != nil {
-		panic(err)
-	}
-	p.Add(h)
+	// Draw histogram, normalized as a density
+	p.Add(util.HistogramToPlotter(hist, true))
 
 	// Overlay PDF
 	pdf := plotter.NewFunction(func(x float64) float64 {
-		// We scale by factor, since the y-axis is not normalized
-		return 3.1e3 * target(x)
+		return target(x)
 	})
 	pdf.Color = color.RGBA{255, 0, 0, 255}
 	p.Add(pdf)
@@ -0,0 +1,197 @@
+package anneal
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/dyedgreen/comp-phys/pkg/casino"
+	"golang.org/x/exp/rand"
+)
+
+// Defaults for simulated annealing
+const defaultAnnealSteps = 10000
+const defaultAnnealAcceptance = 0.8
+const defaultAnnealRatio = 1e-3
+const annealProbes = 100
+
+// AnnealStats holds statistics on a
+// simulated annealing run.
+type AnnealStats struct {
+	// Trials and Accepted count the moves,
+	// totaled over all runs
+	casino.Stats
+	// Temperatures of the first and last step
+	Initial, Final float64
+	// Lowest energy found by every run
+	Energies []float64
+}
+
+func (s AnnealStats) String() string {
+	return fmt.Sprintf("%v runs from T = %v to %v, %v moves (acceptance %v) in %v",
+		len(s.Energies), s.Initial, s.Final, s.Trials, s.AcceptanceRate(), s.Time)
+}
+
+// Annealer implements simulated annealing, which
+// minimises the energy of a Space by running a
+// Metropolis chain from Start, while lowering its
+// temperature from Initial to Final following the
+// Cooling schedule, see:
+//
+//     Kirkpatrick, S.; Gelatt, C. D.; Vecchi, M. P. (1983). "Optimization
+//     by Simulated Annealing". Science 220 (4598): 671–680.
+//
+// If no Initial temperature is given, it is chosen
+// such that uphill moves near Start are accepted with
+// probability Acceptance (defaults to 0.8). The Final
+// temperature defaults to 1/1000 of the initial one.
+//
+// Every seed runs an independent chain concurrently,
+// and the lowest energy state found by any of them
+// is returned. Runs are reproducible given the seeds.
+type Annealer struct {
+	Space Space
+	Start State
+	// Number of moves per run (defaults
+	// to 10000)
+	Steps int
+	// Temperature schedule, if Schedule is not
+	// nil it is used instead of Cooling
+	Cooling        Cooling
+	Initial, Final float64
+	Acceptance     float64
+	Schedule       func(step int) float64
+	// Seeds for the runs, or a Source to
+	// draw the seeds of Runs runs from
+	// (defaults to 1)
+	Seeds  []uint64
+	Source *casino.SeedSource
+	Runs   int
+
+	stats AnnealStats
+}
+
+// Temperature at the given step
+func (a *Annealer) temperature(step int) float64 {
+	if a.Schedule != nil {
+		return a.Schedule(step)
+	}
+	return a.Cooling.Temperature(step, a.Steps, a.Initial, a.Final)
+}
+
+// Estimate the initial temperature from the uphill
+// moves of a random walk from Start
+func (a *Annealer) initial(energy float64, seed uint64) float64 {
+	rng := rand.New(rand.NewSource(casino.NewSeedSource(seed).Seed()))
+	x := a.Start
+	var sum float64
+	var uphill int
+	for i := 0; i < annealProbes; i++ {
+		y := a.Space.Neighbour(x, rng)
+		e := a.Space.Energy(y)
+		if e > energy && !math.IsInf(e, 1) {
+			sum += e - energy
+			uphill++
+		}
+		if !math.IsNaN(e) {
+			x, energy = y, e
+		}
+	}
+	if uphill == 0 || sum == 0 {
+		return 1
+	}
+	return -sum / float64(uphill) / math.Log(a.Acceptance)
+}
+
+// Minimize returns the lowest energy state found
+// and its energy.
+func (a *Annealer) Minimize() (State, float64, error) {
+	start := time.Now()
+	seeds := a.Seeds
+	if seeds == nil && a.Source != nil {
+		runs := a.Runs
+		if runs < 1 {
+			runs = 1
+		}
+		seeds = a.Source.Seeds(runs)
+	}
+	switch {
+	case a.Space == nil || a.Start == nil:
+		return nil, 0, errors.New("need to provide Space and Start")
+	case len(seeds) == 0:
+		return nil, 0, errors.New("need to provide seeds or a source for at least one run")
+	case a.Schedule == nil && (a.Cooling < Geometric || a.Cooling > Logarithmic):
+		return nil, 0, errors.New("unknown cooling schedule")
+	case a.Initial < 0 || a.Final < 0 || (a.Initial > 0 && a.Final > a.Initial):
+		return nil, 0, errors.New("temperatures must be positive and decrease")
+	}
+	// Resolve defaults without changing the
+	// caller's configuration
+	config := *a
+	if config.Steps < 1 {
+		config.Steps = defaultAnnealSteps
+	}
+	if config.Acceptance <= 0 || config.Acceptance >= 1 {
+		config.Acceptance = defaultAnnealAcceptance
+	}
+	energy := a.Space.Energy(a.Start)
+	if config.Schedule == nil {
+		if config.Initial == 0 {
+			config.Initial = config.initial(energy, seeds[0])
+		}
+		if config.Final == 0 {
+			config.Final = config.Initial * defaultAnnealRatio
+		}
+		// A given Final may exceed the estimated Initial
+		if config.Final > config.Initial {
+			return nil, 0, errors.New("temperatures must be positive and decrease")
+		}
+	}
+
+	// Run chains concurrently
+	runs := make([]chain, len(seeds))
+	busyTime := make([]time.Duration, len(seeds))
+	wait := sync.WaitGroup{}
+	wait.Add(len(runs))
+	for k := range runs {
+		runs[k] = newChain(a.Space, a.Start, energy, seeds[k])
+		go func(k int, c *chain) {
+			defer wait.Done()
+			t0 := time.Now()
+			for i := 0; i < config.Steps; i++ {
+				c.step(config.temperature(i))
+			}
+			busyTime[k] = time.Since(t0)
+		}(k, &runs[k])
+	}
+	wait.Wait()
+
+	// Lowest energy over all runs, ties go
+	// to the first run
+	a.stats = AnnealStats{
+		Initial:  config.temperature(0),
+		Final:    config.temperature(config.Steps - 1),
+		Energies: make([]float64, len(runs)),
+	}
+	best := 0
+	for k, c := range runs {
+		a.stats.Trials += c.stats.Trials
+		a.stats.Accepted += c.stats.Accepted
+		a.stats.Energies[k] = c.bestEnergy
+		if c.bestEnergy < runs[best].bestEnergy {
+			best = k
+		}
+	}
+	a.stats.Time = time.Since(start)
+	a.stats.BusyTime = busyTime
+	a.stats.Rate = float64(a.stats.Trials) / a.stats.Time.Seconds()
+	return runs[best].best, runs[best].bestEnergy, nil
+}
+
+// Stats returns statistics on the
+// last run of Minimize.
+func (a *Annealer) Stats() AnnealStats {
+	return a.stats
+}
@@ -0,0 +1,297 @@
+package anneal
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/dyedgreen/comp-phys/pkg/casino"
+	"golang.org/x/exp/rand"
+)
+
+func TestCooling(t *testing.T) {
+	for _, cooling := range []Cooling{Geometric, Linear, Inverse, Logarithmic} {
+		if t0 := cooling.Temperature(0, 100, 10, 0.01); math.Abs(t0-10) > 1e-12 {
+			t.Error(fmt.Sprintf("%v: initial temperature %v should be 10", cooling, t0))
+		}
+		if t1 := cooling.Temperature(99, 100, 10, 0.01); math.Abs(t1-0.01) > 1e-12 {
+			t.Error(fmt.Sprintf("%v: final temperature %v should be 0.01", cooling, t1))
+		}
+		for k := 1; k < 100; k++ {
+			if cooling.Temperature(k, 100, 10, 0.01) >= cooling.Temperature(k-1, 100, 10, 0.01) {
+				t.Error(fmt.Sprintf("%v: temperature should decrease at %v", cooling, k))
+				break
+			}
+		}
+	}
+}
+
+func TestReflect(t *testing.T) {
+	cases := [][2]float64{{0.5, 0.5}, {-0.25, 0.25}, {1.25, 0.75}, {2.5, 0.5}, {-3.25, 0.75}}
+	for _, c := range cases {
+		if y := reflect(c[0], 0, 1); math.Abs(y-c[1]) > 1e-12 {
+			t.Error(fmt.Sprintf("%v should be reflected to %v, got %v", c[0], c[1], y))
+		}
+	}
+}
+
+// Rastrigin function, which has a local minimum
+// at every integer point, and the global minimum
+// 0 at the origin
+func rastrigin(x []float64) float64 {
+	e := 10 * float64(len(x))
+	for _, xi := range x {
+		e += xi*xi - 10*math.Cos(2*math.Pi*xi)
+	}
+	return e
+}
+
+func TestAnnealContinuous(t *testing.T) {
+	for _, cooling := range []Cooling{Geometric, Linear, Inverse, Logarithmic} {
+		a := Annealer{
+			Space: Continuous{
+				Func:        rastrigin,
+				Step:        casino.NormalDist{Mu: 0, Sigma: 0.3},
+				Coordinates: 1,
+				Lower:       []float64{-5.12, -5.12},
+				Upper:       []float64{5.12, 5.12},
+			},
+			Start:   []float64{3.3, -2.7},
+			Steps:   20000,
+			Cooling: cooling,
+			Source:  casino.NewSeedSource(casino.Seed()),
+			Runs:    4,
+		}
+		x, e, err := a.Minimize()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e > 0.5 || math.Abs(x.([]float64)[0]) > 0.1 || math.Abs(x.([]float64)[1]) > 0.1 {
+			t.Error(fmt.Sprintf("%v: found %v with energy %v, should find origin", cooling, x, e))
+		}
+		stats := a.Stats()
+		if stats.Trials != 4*20000 || len(stats.Energies) != 4 || len(stats.BusyTime) != 4 || stats.Time <= 0 {
+			t.Error(fmt.Sprintf("%v: wrong stats %v", cooling, stats))
+		}
+		if rate := stats.AcceptanceRate(); rate <= 0 || rate >= 1 {
+			t.Error(fmt.Sprintf("%v: acceptance rate %v out of range", cooling, rate))
+		}
+		if !(stats.Initial > stats.Final) || math.Abs(stats.Final-stats.Initial*1e-3) > 1e-9*stats.Initial {
+			t.Error(fmt.Sprintf("%v: wrong temperatures %v, %v", cooling, stats.Initial, stats.Final))
+		}
+	}
+}
+
+// Travelling salesman tours over points on a circle,
+// states are permutations of the points. The shortest
+// tour visits the points in order.
+type tour [][2]float64
+
+func (p tour) Energy(x State) float64 {
+	perm := x.([]int)
+	var length float64
+	for i := range perm {
+		a, b := p[perm[i]], p[perm[(i+1)%len(perm)]]
+		length += math.Hypot(a[0]-b[0], a[1]-b[1])
+	}
+	return length
+}
+
+// Reverse a random segment (2-opt move)
+func (p tour) Neighbour(x State, rng *rand.Rand) State {
+	perm := append([]int(nil), x.([]int)...)
+	i, j := rng.Intn(len(perm)), rng.Intn(len(perm))
+	if i > j {
+		i, j = j, i
+	}
+	for ; i < j; i, j = i+1, j-1 {
+		perm[i], perm[j] = perm[j], perm[i]
+	}
+	return perm
+}
+
+func newTour(n int) (tour, []int, float64) {
+	points := make(tour, n)
+	for i := range points {
+		points[i] = [2]float64{math.Cos(2 * math.Pi * float64(i) / float64(n)), math.Sin(2 * math.Pi * float64(i) / float64(n))}
+	}
+	start := rand.New(rand.NewSource(42)).Perm(n)
+	return points, start, float64(n) * 2 * math.Sin(math.Pi/float64(n))
+}
+
+func TestAnnealDiscrete(t *testing.T) {
+	points, start, shortest := newTour(20)
+	original := fmt.Sprint(start)
+	pristine := Annealer{
+		Space: points,
+		Start: start,
+		Steps: 50000,
+		Seeds: casino.Noise(4),
+	}
+	a := pristine
+	x, e, err := a.Minimize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(e-shortest) > 1e-9 || math.Abs(points.Energy(x)-e) > 1e-12 {
+		t.Error(fmt.Sprintf("found tour of length %v, shortest is %v", e, shortest))
+	}
+	if fmt.Sprint(start) != original {
+		t.Error("start should not be modified")
+	}
+	if a.Initial != 0 || a.Final != 0 || a.Acceptance != 0 || a.Steps != 50000 {
+		t.Error(fmt.Sprintf("configuration was changed: %v, %v, %v, %v", a.Initial, a.Final, a.Acceptance, a.Steps))
+	}
+
+	// Same seeds give the same run, including the
+	// estimated initial temperature
+	b := pristine
+	y, f, _ := b.Minimize()
+	sa, sb := a.Stats(), b.Stats()
+	if f != e || fmt.Sprint(x) != fmt.Sprint(y) || sa.Initial != sb.Initial || sa.Accepted != sb.Accepted || fmt.Sprint(sa.Energies) != fmt.Sprint(sb.Energies) {
+		t.Error(fmt.Sprintf("runs should be reproducible: %v, %v", sa, sb))
+	}
+
+	// A new start gets a new initial temperature
+	sorted := make([]int, 20)
+	for i := range sorted {
+		sorted[i] = i
+	}
+	a.Start = sorted
+	a.Minimize()
+	b = pristine
+	b.Start = sorted
+	b.Minimize()
+	if a.Stats().Initial != b.Stats().Initial || a.Stats().Initial == sa.Initial {
+		t.Error(fmt.Sprintf("initial temperature %v should be estimated again as %v", a.Stats().Initial, b.Stats().Initial))
+	}
+
+	// Custom schedule
+	c := pristine
+	c.Schedule = func(step int) float64 { return 1 / (1 + float64(step)) }
+	if _, e, err := c.Minimize(); err != nil || e > points.Energy(start) {
+		t.Error(fmt.Sprintf("custom schedule gives %v, %v", e, err))
+	}
+	if c.Stats().Initial != 1 {
+		t.Error(fmt.Sprintf("initial temperature %v should be 1", c.Stats().Initial))
+	}
+
+	// A given Final above the estimated Initial
+	// would heat the chain
+	hot := pristine
+	hot.Final = 2 * sa.Initial
+	if _, _, err := hot.Minimize(); err == nil {
+		t.Error("should reject a final temperature above the estimated initial one")
+	}
+
+	// A source runs a single chain by default
+	d := pristine
+	d.Seeds, d.Source = nil, casino.NewSeedSource(casino.Seed())
+	if _, _, err := d.Minimize(); err != nil || len(d.Stats().Energies) != 1 {
+		t.Error(fmt.Sprintf("source should run one chain: %v, %v", err, d.Stats()))
+	}
+}
+
+// Tilted double well with a high barrier, whose
+// global minimum is near -1
+func doubleWell(x []float64) float64 {
+	return 10*(x[0]*x[0]-1)*(x[0]*x[0]-1) + x[0]
+}
+
+func TestTempering(t *testing.T) {
+	space := Continuous{
+		Func: doubleWell,
+		Step: casino.NormalDist{Mu: 0, Sigma: 0.2},
+	}
+	pt := Tempering{
+		Space:    space,
+		Start:    []float64{1},
+		Min:      0.05,
+		Max:      20,
+		Replicas: 8,
+		Sweeps:   500,
+		Source:   casino.NewSeedSource(casino.Seed()),
+	}
+	x, e, err := pt.Minimize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if x.([]float64)[0] > -0.9 || e > -0.9 {
+		t.Error(fmt.Sprintf("found %v with energy %v, should find the global minimum", x, e))
+	}
+	stats := pt.Stats()
+	if stats.Trials != 8*500*10 || len(stats.Acceptance) != 8 || len(stats.SwapRates) != 7 || len(stats.BusyTime) != 8 {
+		t.Error(fmt.Sprintf("wrong stats %v", stats))
+	}
+	if stats.Swaps != 500*7/2 {
+		t.Error(fmt.Sprintf("should attempt %v exchanges, not %v", 500*7/2, stats.Swaps))
+	}
+	for i, rate := range stats.SwapRates {
+		if rate <= 0 || rate > 1 {
+			t.Error(fmt.Sprintf("swap rate %v between %v and %v out of range", rate, i, i+1))
+		}
+	}
+	// Hot replicas accept more moves
+	if !(stats.Acceptance[7] > stats.Acceptance[0]) {
+		t.Error(fmt.Sprintf("acceptance should grow with temperature: %v", stats.Acceptance))
+	}
+
+	if pt.Sweeps != 500 || pt.Moves != 0 {
+		t.Error(fmt.Sprintf("configuration was changed: %v, %v", pt.Sweeps, pt.Moves))
+	}
+
+	// A single cold chain stays in the local minimum
+	cold := Tempering{
+		Space:        space,
+		Start:        []float64{1},
+		Temperatures: []float64{0.05},
+		Sweeps:       500,
+		Seeds:        casino.Noise(1),
+	}
+	if _, e, _ := cold.Minimize(); e < 0 {
+		t.Error(fmt.Sprintf("cold chain should not cross the barrier, found %v", e))
+	}
+	if cold.Stats().Swaps != 0 {
+		t.Error("single replica should not exchange")
+	}
+
+	// Same seeds give the same run
+	seeds := casino.Noise(4)
+	var energies []float64
+	for i := 0; i < 2; i++ {
+		pt := Tempering{Space: space, Start: []float64{1}, Temperatures: []float64{0.1, 0.5, 2, 10}, Sweeps: 100, Seeds: seeds}
+		_, e, _ := pt.Minimize()
+		energies = append(energies, e)
+		if i > 0 && (energies[i] != energies[0] || pt.Stats().Exchanged == 0) {
+			t.Error("runs should be reproducible")
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	space := Continuous{Func: rastrigin}
+	start := []float64{1, 1}
+	annealers := []Annealer{
+		{Start: start, Seeds: casino.Noise(1)},
+		{Space: space, Start: start},
+		{Space: space, Start: start, Seeds: casino.Noise(1), Cooling: 9},
+		{Space: space, Start: start, Seeds: casino.Noise(1), Initial: 1, Final: 2},
+	}
+	for i := range annealers {
+		if _, _, err := annealers[i].Minimize(); err == nil {
+			t.Error(fmt.Sprintf("annealer %v should be rejected", i))
+		}
+	}
+	temperings := []Tempering{
+		{Space: space, Start: start, Seeds: casino.Noise(1)},
+		{Space: space, Start: start, Temperatures: []float64{1, 2}, Seeds: casino.Noise(1)},
+		{Space: space, Start: start, Temperatures: []float64{2, 1}, Seeds: casino.Noise(2)},
+		{Space: space, Start: start, Replicas: 2, Min: 0, Max: 1, Seeds: casino.Noise(2)},
+		{Space: space, Temperatures: []float64{1}, Seeds: casino.Noise(1)},
+	}
+	for i := range temperings {
+		if _, _, err := temperings[i].Minimize(); err == nil {
+			t.Error(fmt.Sprintf("tempering %v should be rejected", i))
+		}
+	}
+}
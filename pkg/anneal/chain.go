@@ -0,0 +1,46 @@
+package anneal
+
+import (
+	"math"
+
+	"github.com/dyedgreen/comp-phys/pkg/casino"
+	"golang.org/x/exp/rand"
+)
+
+// A Metropolis chain over a Space, which remembers
+// the lowest energy state it visited
+type chain struct {
+	space      Space
+	rng        *rand.Rand
+	x          State
+	energy     float64
+	best       State
+	bestEnergy float64
+	stats      casino.Stats
+}
+
+func newChain(space Space, x State, energy float64, seed uint64) chain {
+	return chain{
+		space:      space,
+		rng:        rand.New(rand.NewSource(seed)),
+		x:          x,
+		energy:     energy,
+		best:       x,
+		bestEnergy: energy,
+	}
+}
+
+// Take a single step at temperature t. Proposals
+// with NaN energies are always rejected.
+func (c *chain) step(t float64) {
+	c.stats.Trials++
+	y := c.space.Neighbour(c.x, c.rng)
+	e := c.space.Energy(y)
+	if e <= c.energy || c.rng.Float64() < math.Exp(-(e-c.energy)/t) {
+		c.x, c.energy = y, e
+		c.stats.Accepted++
+		if e < c.bestEnergy {
+			c.best, c.bestEnergy = y, e
+		}
+	}
+}
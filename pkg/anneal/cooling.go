@@ -0,0 +1,60 @@
+package anneal
+
+import (
+	"math"
+)
+
+// Cooling selects how the temperature of
+// simulated annealing decreases from the
+// initial temperature T0 to the final
+// temperature T1 over n steps.
+type Cooling int
+
+const (
+	// T0 (T1/T0)^(k/(n-1))
+	Geometric Cooling = iota
+	// T0 + (T1 - T0) k/(n-1)
+	Linear
+	// T0 / (1 + c k), which is the schedule
+	// of fast annealing
+	Inverse
+	// T0 / (1 + c log(1 + k)), which cools
+	// slowly enough to find the global minimum
+	// of a discrete space with high probability
+	Logarithmic
+)
+
+func (c Cooling) String() string {
+	switch c {
+	case Geometric:
+		return "geometric"
+	case Linear:
+		return "linear"
+	case Inverse:
+		return "inverse"
+	case Logarithmic:
+		return "logarithmic"
+	default:
+		return "unknown"
+	}
+}
+
+// Temperature returns the temperature at step k of n.
+// The constants c of the inverse and logarithmic schedules
+// are chosen such that the last step has temperature T1.
+func (c Cooling) Temperature(k, n int, t0, t1 float64) float64 {
+	if n < 2 {
+		return t0
+	}
+	s := float64(k) / float64(n-1)
+	switch c {
+	case Linear:
+		return t0 + (t1-t0)*s
+	case Inverse:
+		return t0 / (1 + (t0/t1-1)*s)
+	case Logarithmic:
+		return t0 / (1 + (t0/t1-1)*math.Log1p(float64(k))/math.Log(float64(n)))
+	default:
+		return t0 * math.Pow(t1/t0, s)
+	}
+}
@@ -0,0 +1,11 @@
+// Package anneal implements global optimisers, which
+// minimise an energy over a search space by sampling
+// from the Boltzmann distribution
+//
+//     p(x) ~ exp(-E(x) / T)
+//
+// at decreasing (simulated annealing) or several
+// coupled (parallel tempering) temperatures T. Search
+// spaces can be continuous (see Continuous) or user
+// defined, see Space.
+package anneal
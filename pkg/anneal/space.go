@@ -0,0 +1,89 @@
+package anneal
+
+import (
+	"math"
+
+	"github.com/dyedgreen/comp-phys/pkg/casino"
+	"golang.org/x/exp/rand"
+)
+
+// State is a point in a search space. The optimisers
+// never modify states, so a Space may share data
+// between neighbouring states.
+type State interface{}
+
+// Space describes a search space, together with the
+// energy which is minimised over it.
+type Space interface {
+	// Energy of a state
+	Energy(x State) float64
+	// Neighbour proposes a new state near x, drawing
+	// its randomness from rng. It must not modify x.
+	// Parallel tempering samples the Boltzmann
+	// distribution only if the proposals are
+	// symmetric.
+	Neighbour(x State, rng *rand.Rand) State
+}
+
+// Continuous is a Space over vectors, whose states are
+// of type []float64. Neighbours are obtained by adding
+// a step drawn from Step to some of the coordinates.
+type Continuous struct {
+	// Energy function
+	Func func([]float64) float64
+	// Distribution of the steps, if this is nil
+	// a standard normal distribution is used
+	Step casino.Distribution
+	// Number of randomly chosen coordinates
+	// changed by every move, if this is not
+	// positive all coordinates change
+	Coordinates int
+	// Optional bounds of every coordinate, states
+	// leaving them are reflected back inside
+	Lower, Upper []float64
+}
+
+func (c Continuous) Energy(x State) float64 {
+	return c.Func(x.([]float64))
+}
+
+func (c Continuous) Neighbour(x State, rng *rand.Rand) State {
+	step := c.Step
+	if step == nil {
+		step = casino.NormalDist{Mu: 0, Sigma: 1}
+	}
+	y := append([]float64(nil), x.([]float64)...)
+	move := func(i int) {
+		y[i] += step.Transform(rng.Float64())
+		if c.Lower != nil && c.Upper != nil {
+			y[i] = reflect(y[i], c.Lower[i], c.Upper[i])
+		}
+	}
+	if c.Coordinates <= 0 || c.Coordinates >= len(y) {
+		for i := range y {
+			move(i)
+		}
+		return y
+	}
+	for k := 0; k < c.Coordinates; k++ {
+		move(rng.Intn(len(y)))
+	}
+	return y
+}
+
+// Reflect x at the boundaries of [a, b], which
+// keeps symmetric proposals symmetric.
+func reflect(x, a, b float64) float64 {
+	width := b - a
+	if !(width > 0) {
+		return a
+	}
+	x = math.Mod(x-a, 2*width)
+	if x < 0 {
+		x += 2 * width
+	}
+	if x > width {
+		x = 2*width - x
+	}
+	return a + x
+}
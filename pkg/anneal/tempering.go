@@ -0,0 +1,218 @@
+package anneal
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/dyedgreen/comp-phys/pkg/casino"
+)
+
+// Defaults for parallel tempering
+const defaultTemperingSweeps = 1000
+const defaultTemperingMoves = 10
+
+// TemperingStats holds statistics on a
+// parallel tempering run.
+type TemperingStats struct {
+	// Trials and Accepted count the moves,
+	// totaled over all replicas
+	casino.Stats
+	// Fraction of moves accepted at every
+	// temperature
+	Acceptance []float64
+	// Number of attempted and accepted
+	// exchanges, totaled over all pairs
+	Swaps, Exchanged int
+	// Fraction of accepted exchanges between
+	// temperatures i and i+1
+	SwapRates []float64
+}
+
+// SwapRate returns the fraction of
+// exchanges which were accepted.
+func (s TemperingStats) SwapRate() float64 {
+	if s.Swaps == 0 {
+		return 0
+	}
+	return float64(s.Exchanged) / float64(s.Swaps)
+}
+
+func (s TemperingStats) String() string {
+	return fmt.Sprintf("%v replicas, %v moves (acceptance %v), %v exchanges (acceptance %v) in %v",
+		len(s.Acceptance), s.Trials, s.AcceptanceRate(), s.Swaps, s.SwapRate(), s.Time)
+}
+
+// Tempering implements parallel tempering (replica
+// exchange), which runs a Metropolis chain at each of
+// several temperatures. After every Moves steps,
+// neighbouring temperatures i and i+1 (alternating
+// between even and odd i) exchange their states with
+// probability
+//
+//     min(1, exp((1/T_i - 1/T_{i+1}) (E_i - E_{i+1})))
+//
+// so low energy states found by the hot replicas,
+// which cross barriers easily, descend to the cold
+// replicas, see:
+//
+//     Earl, D. J.; Deem, M. W. (2005). "Parallel tempering: Theory,
+//     applications, and new perspectives". Phys. Chem. Chem. Phys.
+//     7 (23): 3910–3916.
+//
+// Every replica runs in its own go routine, using its
+// own seed. Exchanges use the random stream of the
+// colder replica, so runs are reproducible given the
+// seeds. If Temperatures is nil, Replicas temperatures
+// are spaced geometrically from Min to Max.
+type Tempering struct {
+	Space Space
+	Start State
+	// Increasing temperatures of the replicas
+	Temperatures []float64
+	Min, Max     float64
+	Replicas     int
+	// Number of exchange rounds (defaults to 1000),
+	// and of moves per replica between exchanges
+	// (defaults to 10)
+	Sweeps, Moves int
+	// Seeds for every replica, or a Source
+	// to draw them from
+	Seeds  []uint64
+	Source *casino.SeedSource
+
+	stats TemperingStats
+}
+
+// Check the configuration and return the
+// temperatures and seeds
+func (pt *Tempering) ladder() ([]float64, []uint64, error) {
+	temps := pt.Temperatures
+	if temps == nil && pt.Replicas > 0 {
+		if !(pt.Min > 0) || pt.Max < pt.Min {
+			return nil, nil, errors.New("need 0 < Min <= Max")
+		}
+		temps = make([]float64, pt.Replicas)
+		for i := range temps {
+			temps[i] = pt.Min
+			if pt.Replicas > 1 {
+				temps[i] *= math.Pow(pt.Max/pt.Min, float64(i)/float64(pt.Replicas-1))
+			}
+		}
+	}
+	seeds := pt.Seeds
+	if seeds == nil && pt.Source != nil {
+		seeds = pt.Source.Seeds(len(temps))
+	}
+	switch {
+	case pt.Space == nil || pt.Start == nil:
+		return nil, nil, errors.New("need to provide Space and Start")
+	case len(temps) == 0:
+		return nil, nil, errors.New("need to provide Temperatures or Replicas")
+	case len(seeds) != len(temps):
+		return nil, nil, errors.New("need to provide a seed for every replica, or a source")
+	}
+	for i, t := range temps {
+		if !(t > 0) || (i > 0 && t <= temps[i-1]) {
+			return nil, nil, errors.New("temperatures must be positive and increase")
+		}
+	}
+	return temps, seeds, nil
+}
+
+// Minimize returns the lowest energy state
+// found by any replica and its energy.
+func (pt *Tempering) Minimize() (State, float64, error) {
+	start := time.Now()
+	temps, seeds, err := pt.ladder()
+	if err != nil {
+		return nil, 0, err
+	}
+	sweeps, moves := pt.Sweeps, pt.Moves
+	if sweeps < 1 {
+		sweeps = defaultTemperingSweeps
+	}
+	if moves < 1 {
+		moves = defaultTemperingMoves
+	}
+
+	// Every replica waits for its turn to move
+	energy := pt.Space.Energy(pt.Start)
+	replicas := make([]chain, len(temps))
+	busyTime := make([]time.Duration, len(temps))
+	turns := make([]chan struct{}, len(temps))
+	wait := sync.WaitGroup{}
+	for k := range replicas {
+		replicas[k] = newChain(pt.Space, pt.Start, energy, seeds[k])
+		turns[k] = make(chan struct{})
+		go func(k int, c *chain) {
+			for range turns[k] {
+				t0 := time.Now()
+				for i := 0; i < moves; i++ {
+					c.step(temps[k])
+				}
+				busyTime[k] += time.Since(t0)
+				wait.Done()
+			}
+		}(k, &replicas[k])
+	}
+
+	swaps := make([]int, len(temps)-1)
+	exchanged := make([]int, len(temps)-1)
+	for s := 0; s < sweeps; s++ {
+		wait.Add(len(replicas))
+		for k := range turns {
+			turns[k] <- struct{}{}
+		}
+		wait.Wait()
+
+		for i := s % 2; i+1 < len(replicas); i += 2 {
+			a, b := &replicas[i], &replicas[i+1]
+			swaps[i]++
+			delta := (1/temps[i] - 1/temps[i+1]) * (a.energy - b.energy)
+			if delta >= 0 || a.rng.Float64() < math.Exp(delta) {
+				a.x, b.x = b.x, a.x
+				a.energy, b.energy = b.energy, a.energy
+				exchanged[i]++
+			}
+		}
+	}
+	for k := range turns {
+		close(turns[k])
+	}
+
+	// Lowest energy over all replicas, ties
+	// go to the coldest replica
+	pt.stats = TemperingStats{
+		Acceptance: make([]float64, len(replicas)),
+		SwapRates:  make([]float64, len(swaps)),
+	}
+	best := 0
+	for k, c := range replicas {
+		pt.stats.Trials += c.stats.Trials
+		pt.stats.Accepted += c.stats.Accepted
+		pt.stats.Acceptance[k] = c.stats.AcceptanceRate()
+		if c.bestEnergy < replicas[best].bestEnergy {
+			best = k
+		}
+	}
+	for i := range swaps {
+		pt.stats.Swaps += swaps[i]
+		pt.stats.Exchanged += exchanged[i]
+		if swaps[i] > 0 {
+			pt.stats.SwapRates[i] = float64(exchanged[i]) / float64(swaps[i])
+		}
+	}
+	pt.stats.Time = time.Since(start)
+	pt.stats.BusyTime = busyTime
+	pt.stats.Rate = float64(pt.stats.Trials) / pt.stats.Time.Seconds()
+	return replicas[best].best, replicas[best].bestEnergy, nil
+}
+
+// Stats returns statistics on the
+// last run of Minimize.
+func (pt *Tempering) Stats() TemperingStats {
+	return pt.stats
+}
@@ -1,23 +1,50 @@
 package casino
 
+import (
+	"errors"
+	"math"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// Default number of proposals in the
+// mixture weighting a sample
+const defaultAPISGroup = 16
+
 // APIS implements the Adaptive
 // Population Importance Sampling
 // algorithm as presented in:
 //
-//    Martino, L.; Elvira, V.; Luengo, D.; Corander, J. (2015-08-01).
-//    "An Adaptive Population Importance Sampler: Learning From Uncertainty".
-//    IEEE Transactions on Signal Processing. 63 (16): 4422–4437.
+//     Martino, L.; Elvira, V.; Luengo, D.; Corander, J. (2015-08-01).
+//     "An Adaptive Population Importance Sampler: Learning From Uncertainty".
+//     IEEE Transactions on Signal Processing. 63 (16): 4422–4437.
 //
 // The algorithm estimates:
-//    I = 1/Z \int f(x) pi(x) dx
+//
+//     I = 1/Z \int f(x) pi(x) dx
+//
 // and
-//    Z = \int pi(x) dx
-// using a set of Gaussian proposal
-// functions.
 //
-// This implementation is not
-// parallelized. The memory requirements
-// are O(len(Mus)).
+//     Z = \int pi(x) dx
+//
+// using a population of proposal
+// distributions from a location-scale
+// Family (Gaussian by default).
+//
+// Samples are weighted with the deterministic
+// mixture of the proposals in their Group of
+// consecutive proposals, rather than the whole
+// population. This keeps the cost of an
+// iteration at O(len(Mus) * Group).
+//
+// Proposals are fixed during an epoch, so
+// the population is split across Workers,
+// which only synchronize between epochs.
+// Every proposal has its own random stream,
+// so the result does not depend on the
+// number of workers (up to rounding). The
+// memory requirements are O(len(Mus)).
 type APIS struct {
 	// Functions used in estimation
 	Function, Pi func(float64) float64
@@ -26,100 +53,221 @@ type APIS struct {
 	// per epoch.
 	Epochs, Iterations int
 
-	// Mean and variances for the
-	// Gaussian family of distributions.
+	// Initial locations and scales of
+	// the proposal distributions.
 	Mus, Sigmas []float64
 
+	// Family creates a proposal with given
+	// location and scale. If this is nil,
+	// NormalDist is used.
+	Family func(mu, sigma float64) Distribution
+
+	// Adapt the scales of the proposals
+	// as well as their locations.
+	AdaptScale bool
+
+	// Number of proposals in the mixture
+	// used to weight a sample (defaults
+	// to 16). Larger groups give better
+	// weights, at a higher cost.
+	Group int
+
+	// Number of concurrent workers, which
+	// defaults to GOMAXPROCS.
+	Workers int
+
 	// Seeds for the samplers
 	// used to sample from the
 	// distributions.
 	Seeds []uint64
+	// Source can be given instead
+	// of Seeds, to draw one seed
+	// per distribution from it.
+	Source *SeedSource
 }
 
-// Estimate returns an estimate for I and Z based
+// StudentTFamily returns a Family of Student-t
+// proposals with nu degrees of freedom. Heavy
+// tailed proposals are more robust, if the
+// target has heavier tails than a Gaussian.
+func StudentTFamily(nu float64) func(mu, sigma float64) Distribution {
+	return func(mu, sigma float64) Distribution {
+		return StudentTDist{nu, mu, sigma}
+	}
+}
+
+// Weighted sums collected by APIS workers
+type apisSums struct {
+	n, w, w2, wf, w2f, w2f2 float64
+}
+
+func (s *apisSums) add(o apisSums) {
+	s.n += o.n
+	s.w += o.w
+	s.w2 += o.w2
+	s.wf += o.wf
+	s.w2f += o.w2f
+	s.w2f2 += o.w2f2
+}
+
+// Estimate returns estimates for I and Z based
 // on the APIS algorithm.
-func (apis *APIS) Estimate() (I, Z float64) {
-	if len(apis.Mus) != len(apis.Sigmas) || len(apis.Mus) != len(apis.Seeds) {
-		panic("need to provide the same amount of mus, sigmas, and seeds")
+//
+// The variance of Z is the variance of the
+// importance weights. The variance of I is
+// obtained with the delta method for the self
+// normalized estimator, and its ESS is Kish's
+// effective sample size.
+func (apis *APIS) Estimate() (I, Z Result, err error) {
+	start := time.Now()
+	seeds := apis.Seeds
+	if seeds == nil && apis.Source != nil {
+		seeds = apis.Source.Seeds(len(apis.Mus))
+	}
+	switch {
+	case apis.Function == nil || apis.Pi == nil:
+		return I, Z, errors.New("need to provide Function and Pi")
+	case len(apis.Mus) == 0:
+		return I, Z, errors.New("need to provide at least one proposal")
+	case len(apis.Mus) != len(apis.Sigmas) || len(apis.Mus) != len(seeds):
+		return I, Z, errors.New("need to provide the same amount of mus, sigmas, and seeds")
+	case apis.Epochs < 1 || apis.Iterations < 1:
+		return I, Z, errors.New("need to run at least one epoch and iteration")
+	}
+	family := apis.Family
+	if family == nil {
+		family = func(mu, sigma float64) Distribution {
+			return NormalDist{mu, sigma}
+		}
+	}
+	group := apis.Group
+	if group < 1 {
+		group = defaultAPISGroup
+	}
+	workers := apis.Workers
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > len(apis.Mus) {
+		workers = len(apis.Mus)
 	}
 
 	// Initialize samplers / distribution family
 	samplers := make([]sampler, len(apis.Mus))
+	sigmas := make([]float64, len(apis.Mus))
 	for i := range samplers {
 		// We use raw-samplers and are able to access and modify the
-		// Gaussian parameters in place.
-		samplers[i] = *NewSampler(NormalDist{apis.Mus[i], apis.Sigmas[i]}, apis.Seeds[i]).(*sampler)
+		// proposal parameters in place.
+		samplers[i] = *NewSampler(family(apis.Mus[i], apis.Sigmas[i]), seeds[i]).(*sampler)
+		sigmas[i] = apis.Sigmas[i]
 	}
 
-	var L float64
-	// as well as I, Z; declared as return values
-
-	z := make([]float64, len(apis.Mus))
-	w := make([]float64, len(apis.Mus))
-
-	W := make([]float64, len(apis.Mus))
-	eta := make([]float64, len(apis.Mus))
+	// Learning memory, per proposal
+	W := make([]float64, len(samplers))
+	eta := make([]float64, len(samplers))
+	eta2 := make([]float64, len(samplers))
 
+	var total apisSums
+	var timing timing
+	timing.grow(workers)
+	sums := make([]apisSums, workers)
+	errs := make([]error, workers)
+	wait := sync.WaitGroup{}
 	for epoch := 0; epoch < apis.Epochs; epoch++ {
-		for iteration := 0; iteration < apis.Iterations; iteration++ {
-			// Importance Sampling part
-
-			// sample z form every member of the family
-			// O(N)
-			for i := range z {
-				z[i] = samplers[i].Sample()
-			}
-
-			// compute weights
-			// O(N^2)
-			// Note: This is a trade-of, could use extra
-			// O(N) memory and do this in O(N) time instead.
-			for i := range z {
-				w[i] = apis.Pi(z[i])
-				var norm float64
-				for j := range samplers {
-					norm += samplers[j].Prob(z[i])
-				}
-				w[i] *= float64(len(samplers)) / norm
-			}
-
-			// Normalize w
-			var S float64
-			for i := range w {
-				S += w[i]
-			}
+		// Every worker runs the epoch for
+		// a contiguous block of proposals
+		wait.Add(workers)
+		for k := 0; k < workers; k++ {
+			go func(k, from, to int) {
+				defer wait.Done()
+				t0 := time.Now()
+				var s apisSums
+				for iteration := 0; iteration < apis.Iterations; iteration++ {
+					for i := from; i < to; i++ {
+						// Importance sampling, weighted with the
+						// deterministic mixture of the group
+						z := samplers[i].Sample()
+						pi := apis.Pi(z)
+						first := i / group * group
+						last := first + group
+						if last > len(samplers) {
+							last = len(samplers)
+						}
+						var norm float64
+						for j := first; j < last; j++ {
+							norm += samplers[j].Prob(z)
+						}
+						var w float64
+						if pi != 0 {
+							// Guard against 0/0
+							if norm == 0 {
+								errs[k] = errors.New("proposal densities vanish where Pi is positive")
+								return
+							}
+							w = pi * float64(last-first) / norm
+						}
+						f := apis.Function(z)
+						s.add(apisSums{1, w, w * w, w * f, w * w * f, w * w * f * f})
 
-			// Improve estimate for I
-			var J float64
-			for i := range z {
-				J += w[i] / S * apis.Function(z[i])
-			}
-
-			I = (L*I + S*J) / (L + S)
-			L += S
-
-			// Learning - Collect information to update proposal
-			for i := range z {
-				rho := apis.Pi(z[i]) / samplers[i].Prob(z[i])
-				if rho+W[i] != 0 {
-					// This needs to be guarded in case Pi has finite
-					// support
-					eta[i] = (W[i]*eta[i] + rho*z[i]) / (W[i] + rho)
+						// Learning - Collect information to update proposal
+						rho := pi / samplers[i].Prob(z)
+						if rho+W[i] != 0 {
+							// This needs to be guarded in case Pi has finite
+							// support
+							eta[i] = (W[i]*eta[i] + rho*z) / (W[i] + rho)
+							eta2[i] = (W[i]*eta2[i] + rho*z*z) / (W[i] + rho)
+						}
+						W[i] += rho
+					}
 				}
-				W[i] += rho
+				sums[k] = s
+				timing.workers[k] += time.Since(t0)
+			}(k, k*len(samplers)/workers, (k+1)*len(samplers)/workers)
+		}
+		wait.Wait()
+		for k := range errs {
+			if errs[k] != nil {
+				return I, Z, errs[k]
 			}
 		}
+		for k := range sums {
+			total.add(sums[k])
+		}
+
 		// Proposal adaptation
 		for i := range samplers {
-			// Update samplers
-			samplers[i].Distribution = NormalDist{eta[i], apis.Sigmas[i]}
+			if W[i] > 0 {
+				if v := eta2[i] - eta[i]*eta[i]; apis.AdaptScale && apis.Iterations > 1 && v > 0 {
+					sigmas[i] = math.Sqrt(v)
+				}
+				samplers[i].Distribution = family(eta[i], sigmas[i])
+			}
 			// Reset memory
 			W[i] = 0
 			eta[i] = 0
+			eta2[i] = 0
 		}
 	}
-	// Compute Z
-	Z = L / float64(len(samplers)) / float64(apis.Epochs*apis.Iterations)
+
+	n := total.n
+	stats := Stats{Trials: int(n)}
+	timing.wall = time.Since(start)
+	timing.fill(&stats)
+
+	// Z is the mean importance weight
+	Z = Result{Value: total.w / n, Stats: stats}
+	Z.Variance = (total.w2 - total.w*total.w/n) / (n - 1)
+	Z.StdErr = math.Sqrt(Z.Variance / n)
+	Z.BatchStdErr, Z.Tau, Z.ESS = Z.StdErr, 1, n
+
+	// I is self normalized, Var(I) = sum w^2 (f - I)^2 / (sum w)^2
+	I = Result{Value: total.wf / total.w, Stats: stats}
+	sq := total.w2f2 - 2*I.Value*total.w2f + I.Value*I.Value*total.w2
+	I.StdErr = math.Sqrt(math.Max(sq, 0)) / total.w
+	I.Variance = I.StdErr * I.StdErr * n
+	I.BatchStdErr = I.StdErr
+	I.ESS = total.w * total.w / total.w2
+	I.Tau = n / I.ESS
 	return
 }
 
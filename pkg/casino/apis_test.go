@@ -14,18 +14,24 @@ func TestAPIS(t *testing.T) {
 	// Can a Gaussian estimate a Gaussian?
 	norm := NormalDist{0, 1}
 	apis := APIS{
-		func(x float64) float64 { return x * x }, norm.Prob,
-		64, 32,
-		mus, sigmas,
-		Noise(10),
+		Function:   func(x float64) float64 { return x * x },
+		Pi:         norm.Prob,
+		Epochs:     64,
+		Iterations: 32,
+		Mus:        mus,
+		Sigmas:     sigmas,
+		Seeds:      Noise(10),
 	}
 
-	I, Z := apis.Estimate()
-	if math.Abs(I-1) > 1e-2 {
-		t.Error(fmt.Sprintf("I = %v should be 1", I))
+	I, Z, err := apis.Estimate()
+	if err != nil {
+		t.Fatal(err)
 	}
-	if math.Abs(Z-1) > 1e-2 {
-		t.Error(fmt.Sprintf("Z = %v should be 1", Z))
+	if math.Abs(I.Value-1) > 1e-2 {
+		t.Error(fmt.Sprintf("I = %v should be 1", I.Value))
+	}
+	if math.Abs(Z.Value-1) > 1e-2 {
+		t.Error(fmt.Sprintf("Z = %v should be 1", Z.Value))
 	}
 
 	// A slightly more interesting problem
@@ -36,12 +42,12 @@ func TestAPIS(t *testing.T) {
 		return 0
 	}
 
-	I, Z = apis.Estimate()
-	if math.Abs(I-0.5) > 1e-2 {
-		t.Error(fmt.Sprintf("I = %v should be 0.5", I))
+	I, Z, _ = apis.Estimate()
+	if math.Abs(I.Value-0.5) > 1e-2 {
+		t.Error(fmt.Sprintf("I = %v should be 0.5", I.Value))
 	}
-	if math.Abs(Z-1) > 1e-2 {
-		t.Error(fmt.Sprintf("Z = %v should be 1", Z))
+	if math.Abs(Z.Value-1) > 1e-2 {
+		t.Error(fmt.Sprintf("Z = %v should be 1", Z.Value))
 	}
 
 	// An even more interesting problem
@@ -57,11 +63,122 @@ func TestAPIS(t *testing.T) {
 		return math.Exp(-lambda * x)
 	}
 
-	I, Z = apis.Estimate()
-	if math.Abs(I-1/lambda) > 1e-1 {
-		t.Error(fmt.Sprintf("I = %v should be %v", I, 1/lambda))
+	I, Z, _ = apis.Estimate()
+	if math.Abs(I.Value-1/lambda) > 1e-1 {
+		t.Error(fmt.Sprintf("I = %v should be %v", I.Value, 1/lambda))
+	}
+	if math.Abs(Z.Value-1/lambda) > 1e-1 {
+		t.Error(fmt.Sprintf("Z = %v should be %v", Z.Value, 1/lambda))
+	}
+}
+
+// Scale adaptation, other families, workers
+// and error estimates.
+func TestAPISGeneral(t *testing.T) {
+	mus, sigmas := APISFamily(NewSampler(UniDistAB{-10, 10}, Seed()), 16)
+	seeds := Noise(16)
+
+	// Heavy tailed target (Cauchy), un-normalized
+	pi := func(x float64) float64 { return 3 / (1 + x*x) }
+	// P(|x| < 1) = 1/2
+	fn := func(x float64) float64 {
+		if math.Abs(x) < 1 {
+			return 1
+		}
+		return 0
+	}
+	var res []Result
+	for _, workers := range []int{1, 3} {
+		apis := APIS{
+			Function: fn, Pi: pi,
+			Epochs: 64, Iterations: 32,
+			Mus: mus, Sigmas: sigmas,
+			Family:     StudentTFamily(2),
+			AdaptScale: true,
+			Workers:    workers,
+			Seeds:      seeds,
+		}
+		I, Z, err := apis.Estimate()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if I.Trials != 64*32*16 {
+			t.Error(fmt.Sprintf("wrong number of trials %v", I.Trials))
+		}
+		if math.Abs(I.Value-0.5) > 5*I.StdErr || I.StdErr > 0.02 {
+			t.Error(fmt.Sprintf("I = %v (+/- %v) should be 0.5", I.Value, I.StdErr))
+		}
+		if math.Abs(Z.Value-3*math.Pi) > 5*Z.StdErr || Z.StdErr > 0.1 {
+			t.Error(fmt.Sprintf("Z = %v (+/- %v) should be %v", Z.Value, Z.StdErr, 3*math.Pi))
+		}
+		if I.ESS <= 0 || I.ESS > float64(I.Trials) {
+			t.Error(fmt.Sprintf("invalid ess %v", I.ESS))
+		}
+		if len(I.BusyTime) != workers || I.Time <= 0 || I.Rate <= 0 {
+			t.Error(fmt.Sprintf("missing timing: %v, %v, %v", I.Time, I.BusyTime, I.Rate))
+		}
+		res = append(res, I, Z)
 	}
-	if math.Abs(Z-1/lambda) > 1e-1 {
-		t.Error(fmt.Sprintf("Z = %v should be %v", I, 1/lambda))
+	// Does not depend on number of workers
+	if math.Abs(res[0].Value-res[2].Value) > 1e-12 || math.Abs(res[1].Value-res[3].Value) > 1e-9 {
+		t.Error(fmt.Sprintf("results depend on workers: %v", res))
 	}
+
+	// Mixtures of single proposals and of the
+	// whole population
+	for _, group := range []int{1, 16} {
+		apis := APIS{
+			Function: fn, Pi: pi,
+			Epochs: 64, Iterations: 32,
+			Mus: mus, Sigmas: sigmas,
+			Family:     StudentTFamily(2),
+			AdaptScale: true,
+			Group:      group,
+			Seeds:      seeds,
+		}
+		I, Z, err := apis.Estimate()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(I.Value-0.5) > 5*I.StdErr || math.Abs(Z.Value-3*math.Pi) > 5*Z.StdErr {
+			t.Error(fmt.Sprintf("group %v: I = %v (+/- %v), Z = %v (+/- %v)", group, I.Value, I.StdErr, Z.Value, Z.StdErr))
+		}
+	}
+
+	// Proposal densities which underflow, where Pi is positive
+	vanish := APIS{
+		Function: fn, Pi: pi,
+		Epochs: 1, Iterations: 1,
+		Mus: mus, Sigmas: sigmas,
+		Family: func(mu, sigma float64) Distribution {
+			return underflowDist{NormalDist{mu, sigma}}
+		},
+		Seeds: seeds,
+	}
+	if _, _, err := vanish.Estimate(); err == nil {
+		t.Error("vanishing proposal densities should be rejected")
+	}
+
+	// Invalid configurations
+	bad := []APIS{
+		{Function: fn, Pi: pi, Epochs: 1, Iterations: 1, Mus: mus, Sigmas: sigmas[:3], Seeds: seeds},
+		{Function: fn, Pi: pi, Epochs: 1, Iterations: 1, Mus: mus, Sigmas: sigmas},
+		{Function: fn, Pi: pi, Epochs: 0, Iterations: 1, Mus: mus, Sigmas: sigmas, Seeds: seeds},
+		{Function: fn, Epochs: 1, Iterations: 1, Mus: mus, Sigmas: sigmas, Seeds: seeds},
+	}
+	for i := range bad {
+		if _, _, err := bad[i].Estimate(); err == nil {
+			t.Error(fmt.Sprintf("configuration %v should be rejected", i))
+		}
+	}
+}
+
+// Distribution whose density underflows
+// everywhere.
+type underflowDist struct {
+	NormalDist
+}
+
+func (underflowDist) Prob(float64) float64 {
+	return 0
 }
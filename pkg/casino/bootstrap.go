@@ -0,0 +1,326 @@
+package casino
+
+import (
+	"errors"
+	"math"
+	"runtime"
+	"sort"
+	"sync"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/stat"
+)
+
+// Default number of bootstrap resamples
+const defaultBootstrapResamples = 1000
+
+// Observations returns the rows of the given
+// columns, i.e. obs[i][j] = columns[j][i].
+// This converts parallel sample slices (e.g.
+// weights and weighted function values) into
+// observations for Bootstrap and Jackknife.
+func Observations(columns ...[]float64) [][]float64 {
+	if len(columns) == 0 {
+		return nil
+	}
+	obs := make([][]float64, len(columns[0]))
+	for i := range obs {
+		obs[i] = make([]float64, len(columns))
+		for j := range columns {
+			obs[i][j] = columns[j][i]
+		}
+	}
+	return obs
+}
+
+// Split observations into blocks of the given
+// size, dropping the incomplete last block. The
+// returned observations are those covered by the
+// blocks.
+func blocks(obs [][]float64, size int) ([][]float64, [][][]float64, error) {
+	if size < 1 {
+		size = 1
+	}
+	n := len(obs) / size
+	if n < 2 {
+		return nil, nil, errors.New("need at least two blocks of observations")
+	}
+	blocks := make([][][]float64, n)
+	for i := range blocks {
+		blocks[i] = obs[i*size : (i+1)*size]
+	}
+	return obs[:n*size], blocks, nil
+}
+
+// Bootstrap estimates the error of a statistic,
+// by evaluating it on observations resampled
+// with replacement:
+//
+//     Efron, B.; Tibshirani, R. (1993). "An Introduction
+//     to the Bootstrap". Chapman & Hall.
+//
+// Every observation is a slice, which allows for
+// statistics of several quantities (e.g. the ratio
+// of two means). Correlated observations (e.g. from
+// a Markov chain) should be resampled in Blocks of
+// consecutive observations, which are longer than
+// the autocorrelation time. An incomplete last block
+// is dropped, including from the statistic of the
+// original observations.
+//
+// Every resample uses its own random stream, so
+// the result does not depend on the number of
+// Workers.
+type Bootstrap struct {
+	// Statistic computed from observations,
+	// which must be safe for concurrent use and
+	// not retain or modify obs.
+	Statistic func(obs [][]float64) float64
+	// Number of resamples, defaults to 1000
+	Resamples int
+	// Size of the resampled blocks, defaults
+	// to 1 (i.e. independent observations)
+	Block int
+	// Number of concurrent workers, which
+	// defaults to GOMAXPROCS.
+	Workers int
+
+	// Seeds for every resample, or a
+	// Source to draw them from
+	Seeds  []uint64
+	Source *SeedSource
+}
+
+// BootstrapResult contains the statistic of the
+// original observations and its bootstrap estimates.
+type BootstrapResult struct {
+	Value, Bias, StdErr float64
+	// Sorted statistics of the resamples
+	Replicates []float64
+
+	// Jackknife estimates for BCa, which are
+	// computed on demand
+	jackknife *lazyJackknife
+}
+
+// Jackknife replicates, which are only evaluated
+// once they are needed, since they cost one
+// evaluation of the statistic per block
+type lazyJackknife struct {
+	once       sync.Once
+	blocks     [][][]float64
+	statistic  func([][]float64) float64
+	workers    int
+	replicates []float64
+}
+
+func (j *lazyJackknife) get() []float64 {
+	if j == nil {
+		return nil
+	}
+	j.once.Do(func() {
+		j.replicates = jackknifeReplicates(j.blocks, j.statistic, j.workers)
+		j.blocks = nil
+	})
+	return j.replicates
+}
+
+// Estimate evaluates the statistic on the given
+// observations and their resamples.
+func (b *Bootstrap) Estimate(obs [][]float64) (BootstrapResult, error) {
+	var res BootstrapResult
+	if b.Statistic == nil {
+		return res, errors.New("need to provide Statistic")
+	}
+	resamples := b.Resamples
+	if resamples < 1 {
+		resamples = defaultBootstrapResamples
+	}
+	seeds := b.Seeds
+	if seeds == nil && b.Source != nil {
+		seeds = b.Source.Seeds(resamples)
+	}
+	if len(seeds) != resamples {
+		return res, errors.New("need to provide a seed for every resample, or a source")
+	}
+	obs, blocks, err := blocks(obs, b.Block)
+	if err != nil {
+		return res, err
+	}
+
+	res.Value = b.Statistic(obs)
+	res.Replicates = make([]float64, resamples)
+	parallel(b.Workers, resamples, func(from, to int) {
+		size := len(blocks[0])
+		sample := make([][]float64, len(blocks)*size)
+		for r := from; r < to; r++ {
+			rng := rand.New(rand.NewSource(seeds[r]))
+			for k := range blocks {
+				copy(sample[k*size:], blocks[rng.Intn(len(blocks))])
+			}
+			res.Replicates[r] = b.Statistic(sample)
+		}
+	})
+	sort.Float64s(res.Replicates)
+
+	mean, std := stat.MeanStdDev(res.Replicates, nil)
+	res.Bias = mean - res.Value
+	res.StdErr = std
+
+	res.jackknife = &lazyJackknife{blocks: blocks, statistic: b.Statistic, workers: b.Workers}
+	return res, nil
+}
+
+// Percentile returns the interval between the
+// (1-level)/2 and (1+level)/2 quantiles of the
+// bootstrap replicates.
+func (r BootstrapResult) Percentile(level float64) (float64, float64) {
+	alpha := (1 - level) / 2
+	return r.quantile(alpha), r.quantile(1 - alpha)
+}
+
+// BCa returns the bias corrected and accelerated
+// interval, which corrects the percentile interval
+// for the bias and skewness of the statistic. The
+// acceleration is estimated with the jackknife, which
+// evaluates the statistic once per block on the first
+// call and retains the observations until then.
+func (r BootstrapResult) BCa(level float64) (float64, float64) {
+	// Bias correction, the fraction is kept away
+	// from 0 and 1 where z0 is infinite
+	n := float64(len(r.Replicates))
+	below := float64(sort.SearchFloat64s(r.Replicates, r.Value))
+	z0 := stdNormalInv(math.Max(0.5/n, math.Min(1-0.5/n, below/n)))
+
+	// Acceleration
+	jackknife := r.jackknife.get()
+	mean := stat.Mean(jackknife, nil)
+	var num, den float64
+	for _, j := range jackknife {
+		d := mean - j
+		num += d * d * d
+		den += d * d
+	}
+	var a float64
+	if den > 0 {
+		a = num / (6 * math.Pow(den, 1.5))
+	}
+
+	adjust := func(p float64) float64 {
+		z := stdNormalInv(p)
+		return stdNormalCDF(z0 + (z0+z)/(1-a*(z0+z)))
+	}
+	alpha := (1 - level) / 2
+	return r.quantile(adjust(alpha)), r.quantile(adjust(1 - alpha))
+}
+
+func (r BootstrapResult) quantile(p float64) float64 {
+	if math.IsNaN(p) {
+		// Degenerate replicates
+		return r.Value
+	}
+	p = math.Max(0, math.Min(1, p))
+	return stat.Quantile(p, stat.LinInterp, r.Replicates, nil)
+}
+
+// Jackknife estimates the bias and error of a
+// statistic, by evaluating it with every block
+// of observations left out:
+//
+//     Quenouille, M. H. (1956). "Notes on Bias in Estimation".
+//     Biometrika. 43 (3-4): 353–360.
+//
+// This is deterministic, and requires one evaluation
+// of the statistic per block. See Bootstrap.
+type Jackknife struct {
+	// Statistic computed from observations,
+	// see Bootstrap
+	Statistic func(obs [][]float64) float64
+	// Size of the blocks left out, defaults
+	// to 1 (i.e. independent observations)
+	Block int
+	// Number of concurrent workers, which
+	// defaults to GOMAXPROCS.
+	Workers int
+}
+
+// JackknifeResult contains the statistic of
+// the original observations and its jackknife
+// estimates.
+type JackknifeResult struct {
+	Value, Bias, StdErr float64
+	// Statistics with every block left out
+	Replicates []float64
+}
+
+// Corrected returns the bias corrected
+// statistic.
+func (r JackknifeResult) Corrected() float64 {
+	return r.Value - r.Bias
+}
+
+// Estimate evaluates the statistic on the given
+// observations, leaving out every block in turn.
+func (j *Jackknife) Estimate(obs [][]float64) (JackknifeResult, error) {
+	var res JackknifeResult
+	if j.Statistic == nil {
+		return res, errors.New("need to provide Statistic")
+	}
+	obs, blocks, err := blocks(obs, j.Block)
+	if err != nil {
+		return res, err
+	}
+	res.Value = j.Statistic(obs)
+	res.Replicates = jackknifeReplicates(blocks, j.Statistic, j.Workers)
+
+	n := float64(len(blocks))
+	mean := stat.Mean(res.Replicates, nil)
+	var sq float64
+	for _, r := range res.Replicates {
+		sq += (r - mean) * (r - mean)
+	}
+	res.Bias = (n - 1) * (mean - res.Value)
+	res.StdErr = math.Sqrt((n - 1) / n * sq)
+	return res, nil
+}
+
+// Evaluate statistic with every block left out
+func jackknifeReplicates(blocks [][][]float64, statistic func([][]float64) float64, workers int) []float64 {
+	replicates := make([]float64, len(blocks))
+	parallel(workers, len(blocks), func(from, to int) {
+		size := len(blocks[0])
+		sample := make([][]float64, (len(blocks)-1)*size)
+		for i := from; i < to; i++ {
+			k := 0
+			for b := range blocks {
+				if b != i {
+					copy(sample[k*size:], blocks[b])
+					k++
+				}
+			}
+			replicates[i] = statistic(sample)
+		}
+	})
+	return replicates
+}
+
+// Run fn on contiguous ranges of [0, n), with
+// at most the given number of workers, which
+// defaults to GOMAXPROCS.
+func parallel(workers, n int, fn func(from, to int)) {
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > n {
+		workers = n
+	}
+	wait := sync.WaitGroup{}
+	wait.Add(workers)
+	for k := 0; k < workers; k++ {
+		go func(from, to int) {
+			defer wait.Done()
+			fn(from, to)
+		}(k*n/workers, (k+1)*n/workers)
+	}
+	wait.Wait()
+}
@@ -0,0 +1,238 @@
+package casino
+
+import (
+	"fmt"
+	"math"
+	"sync/atomic"
+	"testing"
+
+	"gonum.org/v1/gonum/stat"
+)
+
+func mean0(obs [][]float64) float64 {
+	var s float64
+	for _, o := range obs {
+		s += o[0]
+	}
+	return s / float64(len(obs))
+}
+
+func TestBootstrap(t *testing.T) {
+	s := NewSampler(NormalDist{0, 1}, Seed())
+	x := make([]float64, 2000)
+	y := make([]float64, 2000)
+	for i := range x {
+		x[i] = s.Sample()
+		y[i] = 2 + 0.5*s.Sample()
+	}
+	obs := Observations(x, y)
+
+	// Mean, compared to the standard error
+	stderr := stat.StdDev(x, nil) / math.Sqrt(float64(len(x)))
+	boot := Bootstrap{Statistic: mean0, Source: NewSeedSource(Seed())}
+	res, err := boot.Estimate(obs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(res.StdErr/stderr-1) > 0.1 {
+		t.Error(fmt.Sprintf("bootstrap error %v should be %v", res.StdErr, stderr))
+	}
+	jack := Jackknife{Statistic: mean0}
+	jres, err := jack.Estimate(obs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(jres.StdErr/stderr-1) > 1e-9 || math.Abs(jres.Bias) > 1e-12 {
+		t.Error(fmt.Sprintf("jackknife error %v, bias %v should be %v, 0", jres.StdErr, jres.Bias, stderr))
+	}
+
+	// Ratio of means, compared to the delta method
+	ratio := func(obs [][]float64) float64 {
+		var a, b float64
+		for _, o := range obs {
+			a += o[0] + 3
+			b += o[1]
+		}
+		return a / b
+	}
+	r := (stat.Mean(x, nil) + 3) / stat.Mean(y, nil)
+	delta := math.Sqrt(stat.Variance(x, nil)/(4*4)+r*r*stat.Variance(y, nil)/(4*4)) / math.Sqrt(float64(len(x))) * 2
+	boot.Statistic, jack.Statistic = ratio, ratio
+	res, _ = boot.Estimate(obs)
+	jres, _ = jack.Estimate(obs)
+	for _, e := range []float64{res.StdErr, jres.StdErr} {
+		if math.Abs(e/delta-1) > 0.1 {
+			t.Error(fmt.Sprintf("error of the ratio %v should be %v", e, delta))
+		}
+	}
+
+	// Jackknife removes the bias of the plug-in variance
+	variance := func(obs [][]float64) float64 {
+		m := mean0(obs)
+		var s float64
+		for _, o := range obs {
+			s += (o[0] - m) * (o[0] - m)
+		}
+		return s / float64(len(obs))
+	}
+	jack.Statistic = variance
+	jres, _ = jack.Estimate(obs)
+	if v := stat.Variance(x, nil); math.Abs(jres.Corrected()-v) > 1e-9 {
+		t.Error(fmt.Sprintf("corrected variance %v should be %v", jres.Corrected(), v))
+	}
+
+	// Results do not depend on the workers
+	seeds := Noise(200)
+	a := Bootstrap{Statistic: mean0, Resamples: 200, Seeds: seeds, Workers: 1}
+	b := Bootstrap{Statistic: mean0, Resamples: 200, Seeds: seeds, Workers: 7}
+	resA, _ := a.Estimate(obs)
+	resB, _ := b.Estimate(obs)
+	for i := range resA.Replicates {
+		if resA.Replicates[i] != resB.Replicates[i] {
+			t.Fatal("replicates should not depend on the number of workers")
+		}
+	}
+
+	if _, err := (&Bootstrap{Statistic: mean0}).Estimate(obs); err == nil {
+		t.Error("should reject missing seeds")
+	}
+	if _, err := (&Jackknife{Statistic: mean0, Block: 2000}).Estimate(obs); err == nil {
+		t.Error("should reject single block")
+	}
+}
+
+// The jackknife for BCa is only evaluated once
+// it is needed, since it costs one evaluation
+// of the statistic per observation.
+func TestBootstrapLazyJackknife(t *testing.T) {
+	var calls int64
+	statistic := func(obs [][]float64) float64 {
+		atomic.AddInt64(&calls, 1)
+		return mean0(obs)
+	}
+	x := make([]float64, 1000)
+	s := NewSampler(ExponentialDist{1}, Seed())
+	for i := range x {
+		x[i] = s.Sample()
+	}
+	boot := Bootstrap{Statistic: statistic, Resamples: 100, Source: NewSeedSource(Seed())}
+	res, err := boot.Estimate(Observations(x))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Percentile(0.9)
+	if calls != 1+100 {
+		t.Error(fmt.Sprintf("statistic evaluated %v times, should be %v", calls, 1+100))
+	}
+	lo, hi := res.BCa(0.9)
+	res.BCa(0.95)
+	if calls != 1+100+1000 {
+		t.Error(fmt.Sprintf("statistic evaluated %v times, should be %v", calls, 1+100+1000))
+	}
+	if !(lo < res.Value && res.Value < hi) {
+		t.Error(fmt.Sprintf("BCa interval [%v, %v] should contain %v", lo, hi, res.Value))
+	}
+}
+
+// Intervals cover the true value of a skewed
+// statistic at approximately the given level.
+func TestBootstrapIntervals(t *testing.T) {
+	const reps = 200
+	s := NewSampler(ExponentialDist{1}, Seed())
+	src := NewSeedSource(Seed())
+	var percentile, bca int
+	for k := 0; k < reps; k++ {
+		x := make([]float64, 20)
+		for i := range x {
+			x[i] = s.Sample()
+		}
+		boot := Bootstrap{Statistic: mean0, Resamples: 500, Source: src}
+		res, err := boot.Estimate(Observations(x))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if lo, hi := res.Percentile(0.9); lo <= 1 && 1 <= hi {
+			percentile++
+		}
+		if lo, hi := res.BCa(0.9); lo <= 1 && 1 <= hi {
+			bca++
+		}
+	}
+	for _, c := range []int{percentile, bca} {
+		if c < 0.8*reps || c > 0.97*reps {
+			t.Error(fmt.Sprintf("90%% interval covered in %v of %v cases", c, reps))
+		}
+	}
+}
+
+// Blocks account for correlated observations.
+func TestBootstrapBlocks(t *testing.T) {
+	const phi = 0.9
+	gen := ar1{phi: phi, s: NewSampler(NormalDist{0, 1}, Seed())}
+	x := make([]float64, 5000)
+	for i := range x {
+		x[i] = gen.Sample()
+	}
+	// Standard error of the mean of an AR(1)
+	// process with unit variance
+	stderr := math.Sqrt((1+phi)/(1-phi)) / math.Sqrt(float64(len(x)))
+
+	for _, block := range []int{1, 100} {
+		boot := Bootstrap{Statistic: mean0, Block: block, Resamples: 300, Source: NewSeedSource(Seed())}
+		res, err := boot.Estimate(Observations(x))
+		if err != nil {
+			t.Fatal(err)
+		}
+		jack := Jackknife{Statistic: mean0, Block: block}
+		jres, _ := jack.Estimate(Observations(x))
+		for _, e := range []float64{res.StdErr, jres.StdErr} {
+			if block == 1 && e > stderr/3 {
+				t.Error(fmt.Sprintf("independent resampling error %v should underestimate %v", e, stderr))
+			}
+			if block > 1 && math.Abs(e/stderr-1) > 0.3 {
+				t.Error(fmt.Sprintf("block error %v should be %v", e, stderr))
+			}
+		}
+	}
+}
+
+// The statistic of the original observations
+// uses the same observations as the resamples.
+func TestBootstrapIncompleteBlock(t *testing.T) {
+	obs := Observations([]float64{1, 2, 3, 4, 100})
+	boot := Bootstrap{Statistic: mean0, Block: 2, Resamples: 10, Source: NewSeedSource(Seed())}
+	res, err := boot.Estimate(obs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jack := Jackknife{Statistic: mean0, Block: 2}
+	jres, err := jack.Estimate(obs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []float64{res.Value, jres.Value} {
+		if v != 2.5 {
+			t.Error(fmt.Sprintf("value %v should exclude the incomplete block", v))
+		}
+	}
+	for _, r := range res.Replicates {
+		if r > 4 {
+			t.Error(fmt.Sprintf("replicate %v includes the incomplete block", r))
+		}
+	}
+}
+
+// The bias correction stays finite, if no
+// replicate falls below the value.
+func TestBootstrapBCaEdge(t *testing.T) {
+	// Skewed jackknife, so the acceleration is not zero
+	jackknife := &lazyJackknife{blocks: [][][]float64{{{1}}, {{2}}, {{10}}}, statistic: mean0, workers: 1}
+	res := BootstrapResult{Value: 0, Replicates: make([]float64, 100), jackknife: jackknife}
+	for i := range res.Replicates {
+		res.Replicates[i] = float64(i + 1)
+	}
+	lo, hi := res.BCa(0.9)
+	if !(1 <= lo && lo <= hi && hi < 2) {
+		t.Error(fmt.Sprintf("BCa interval [%v, %v] should be at the smallest replicates", lo, hi))
+	}
+}
@@ -0,0 +1,346 @@
+package casino
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mathext"
+)
+
+// Implements an exponential distribution
+// with rate Lambda. Lambda must be positive.
+type ExponentialDist struct {
+	Lambda float64
+}
+
+func (d ExponentialDist) Transform(x float64) float64 {
+	return -math.Log1p(-x) / d.Lambda
+}
+
+func (d ExponentialDist) Prob(x float64) float64 {
+	if x < 0 {
+		return 0
+	}
+	return d.Lambda * math.Exp(-d.Lambda*x)
+}
+
+func (d ExponentialDist) Support() (float64, float64) {
+	return 0, math.Inf(+1)
+}
+
+func (d ExponentialDist) CDF(x float64) float64 {
+	if x < 0 {
+		return 0
+	}
+	return -math.Expm1(-d.Lambda * x)
+}
+
+// Implements a Cauchy distribution with
+// location X0 and scale Gamma. Gamma must
+// be positive.
+type CauchyDist struct {
+	X0, Gamma float64
+}
+
+func (d CauchyDist) Transform(x float64) float64 {
+	return d.X0 + d.Gamma*math.Tan(math.Pi*(x-0.5))
+}
+
+func (d CauchyDist) Prob(x float64) float64 {
+	y := (x - d.X0) / d.Gamma
+	return 1 / (math.Pi * d.Gamma * (1 + y*y))
+}
+
+func (d CauchyDist) Support() (float64, float64) {
+	return math.Inf(-1), math.Inf(+1)
+}
+
+func (d CauchyDist) CDF(x float64) float64 {
+	return 0.5 + math.Atan((x-d.X0)/d.Gamma)/math.Pi
+}
+
+// Implements a Laplace distribution with
+// location Mu and scale B. B must be positive.
+type LaplaceDist struct {
+	Mu, B float64
+}
+
+func (d LaplaceDist) Transform(x float64) float64 {
+	// Avoid -Inf at 0, see NormalDist
+	if x <= 0 {
+		x = 0x1p-54
+	}
+	if x < 0.5 {
+		return d.Mu + d.B*math.Log(2*x)
+	}
+	return d.Mu - d.B*math.Log(2-2*x)
+}
+
+func (d LaplaceDist) Prob(x float64) float64 {
+	return math.Exp(-math.Abs(x-d.Mu)/d.B) / (2 * d.B)
+}
+
+func (d LaplaceDist) Support() (float64, float64) {
+	return math.Inf(-1), math.Inf(+1)
+}
+
+func (d LaplaceDist) CDF(x float64) float64 {
+	if x < d.Mu {
+		return 0.5 * math.Exp((x-d.Mu)/d.B)
+	}
+	return 1 - 0.5*math.Exp(-(x-d.Mu)/d.B)
+}
+
+// Implements a Student's t-distribution with
+// Nu degrees of freedom, location Mu and scale
+// Sigma. Nu and Sigma must be positive.
+//
+// The inverse CDF is computed from the inverse
+// of the regularized incomplete beta function.
+type StudentTDist struct {
+	Nu, Mu, Sigma float64
+}
+
+func (d StudentTDist) Transform(x float64) float64 {
+	// F(t) = 1 - I_{nu / (t^2 + nu)}(nu/2, 1/2) / 2 for t > 0
+	if x == 0.5 {
+		return d.Mu
+	}
+	// Avoid -Inf at 0, see NormalDist
+	if x <= 0 {
+		x = 0x1p-54
+	}
+	var t float64
+	if x > 0.5 {
+		y := mathext.InvRegIncBeta(d.Nu/2, 0.5, 2*(1-x))
+		t = math.Sqrt(d.Nu * (1 - y) / y)
+	} else {
+		y := mathext.InvRegIncBeta(d.Nu/2, 0.5, 2*x)
+		t = -math.Sqrt(d.Nu * (1 - y) / y)
+	}
+	return d.Mu + d.Sigma*t
+}
+
+func (d StudentTDist) Prob(x float64) float64 {
+	t := (x - d.Mu) / d.Sigma
+	lg1, _ := math.Lgamma((d.Nu + 1) / 2)
+	lg2, _ := math.Lgamma(d.Nu / 2)
+	return math.Exp(lg1-lg2-(d.Nu+1)/2*math.Log1p(t*t/d.Nu)) / (math.Sqrt(d.Nu*math.Pi) * d.Sigma)
+}
+
+func (d StudentTDist) Support() (float64, float64) {
+	return math.Inf(-1), math.Inf(+1)
+}
+
+func (d StudentTDist) CDF(x float64) float64 {
+	t := (x - d.Mu) / d.Sigma
+	tail := 0.5 * mathext.RegIncBeta(d.Nu/2, 0.5, d.Nu/(t*t+d.Nu))
+	if t > 0 {
+		return 1 - tail
+	}
+	return tail
+}
+
+// Implements a Gamma distribution with shape
+// Alpha and rate Beta. Both must be positive.
+//
+// The inverse CDF is computed from the inverse
+// of the regularized incomplete gamma function.
+type GammaDist struct {
+	Alpha, Beta float64
+}
+
+func (d GammaDist) Transform(x float64) float64 {
+	return mathext.GammaIncRegInv(d.Alpha, x) / d.Beta
+}
+
+func (d GammaDist) Prob(x float64) float64 {
+	if x < 0 {
+		return 0
+	}
+	lg, _ := math.Lgamma(d.Alpha)
+	logP := d.Alpha*math.Log(d.Beta) - lg - d.Beta*x
+	// Drop zero exponents, which give 0*(-Inf) at x = 0
+	if d.Alpha != 1 {
+		logP += (d.Alpha - 1) * math.Log(x)
+	}
+	return math.Exp(logP)
+}
+
+func (d GammaDist) Support() (float64, float64) {
+	return 0, math.Inf(+1)
+}
+
+func (d GammaDist) CDF(x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	return mathext.GammaIncReg(d.Alpha, d.Beta*x)
+}
+
+// Implements a Beta distribution with shape
+// parameters Alpha and Beta. Both must be
+// positive.
+//
+// The inverse CDF is computed from the inverse
+// of the regularized incomplete beta function.
+type BetaDist struct {
+	Alpha, Beta float64
+}
+
+func (d BetaDist) Transform(x float64) float64 {
+	return mathext.InvRegIncBeta(d.Alpha, d.Beta, x)
+}
+
+func (d BetaDist) Prob(x float64) float64 {
+	if x < 0 || x > 1 {
+		return 0
+	}
+	logP := -mathext.Lbeta(d.Alpha, d.Beta)
+	// Drop zero exponents, which give 0*(-Inf) at the endpoints
+	if d.Alpha != 1 {
+		logP += (d.Alpha - 1) * math.Log(x)
+	}
+	if d.Beta != 1 {
+		logP += (d.Beta - 1) * math.Log1p(-x)
+	}
+	return math.Exp(logP)
+}
+
+func (d BetaDist) Support() (float64, float64) {
+	return 0, 1
+}
+
+func (d BetaDist) CDF(x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+	return mathext.RegIncBeta(d.Alpha, d.Beta, x)
+}
+
+// Implements a log-normal distribution, i.e.
+// the distribution of exp(y) where y is normal
+// with mean Mu and standard deviation Sigma.
+// Sigma must be positive.
+type LogNormalDist struct {
+	Mu, Sigma float64
+}
+
+func (d LogNormalDist) Transform(x float64) float64 {
+	return math.Exp(NormalDist{d.Mu, d.Sigma}.Transform(x))
+}
+
+func (d LogNormalDist) Prob(x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	return NormalDist{d.Mu, d.Sigma}.Prob(math.Log(x)) / x
+}
+
+func (d LogNormalDist) Support() (float64, float64) {
+	return 0, math.Inf(+1)
+}
+
+func (d LogNormalDist) CDF(x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	return NormalDist{d.Mu, d.Sigma}.CDF(math.Log(x))
+}
+
+// Implements a Weibull distribution with
+// shape K and scale Lambda. Both must be
+// positive.
+type WeibullDist struct {
+	K, Lambda float64
+}
+
+func (d WeibullDist) Transform(x float64) float64 {
+	return d.Lambda * math.Pow(-math.Log1p(-x), 1/d.K)
+}
+
+func (d WeibullDist) Prob(x float64) float64 {
+	if x < 0 {
+		return 0
+	}
+	y := x / d.Lambda
+	return d.K / d.Lambda * math.Pow(y, d.K-1) * math.Exp(-math.Pow(y, d.K))
+}
+
+func (d WeibullDist) Support() (float64, float64) {
+	return 0, math.Inf(+1)
+}
+
+func (d WeibullDist) CDF(x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	return -math.Expm1(-math.Pow(x/d.Lambda, d.K))
+}
+
+// Implements a normal distribution with
+// mean Mu and standard deviation Sigma,
+// truncated to [A, B]. Sigma must be positive
+// and A < B.
+//
+// The inverse CDF is evaluated in whichever
+// tail is more accurate, so that the bounds
+// may lie far from the mean.
+type TruncatedNormalDist struct {
+	Mu, Sigma, A, B float64
+}
+
+// Standard normal CDF and its inverse,
+// both retain accuracy in the lower tail.
+func stdNormalCDF(x float64) float64 {
+	return 0.5 * math.Erfc(-x/math.Sqrt2)
+}
+
+func stdNormalInv(p float64) float64 {
+	return mathext.NormalQuantile(math.Max(0, math.Min(1, p)))
+}
+
+func (d TruncatedNormalDist) Transform(x float64) float64 {
+	a, b := (d.A-d.Mu)/d.Sigma, (d.B-d.Mu)/d.Sigma
+	// Work in the lower tail, where the CDF is accurate,
+	// by reflecting the distribution if needed
+	sign := 1.0
+	if a > 0 {
+		a, b = -b, -a
+		x = 1 - x
+		sign = -1
+	}
+	pa, pb := stdNormalCDF(a), stdNormalCDF(b)
+	y := stdNormalInv(pa + x*(pb-pa))
+	return d.Mu + sign*d.Sigma*math.Max(a, math.Min(b, y))
+}
+
+func (d TruncatedNormalDist) Prob(x float64) float64 {
+	if x < d.A || x > d.B {
+		return 0
+	}
+	a, b := (d.A-d.Mu)/d.Sigma, (d.B-d.Mu)/d.Sigma
+	var z float64
+	if a > 0 {
+		z = stdNormalCDF(-a) - stdNormalCDF(-b)
+	} else {
+		z = stdNormalCDF(b) - stdNormalCDF(a)
+	}
+	return NormalDist{d.Mu, d.Sigma}.Prob(x) / z
+}
+
+func (d TruncatedNormalDist) Support() (float64, float64) {
+	return d.A, d.B
+}
+
+func (d TruncatedNormalDist) CDF(x float64) float64 {
+	x = math.Max(d.A, math.Min(d.B, x))
+	a, b, z := (d.A-d.Mu)/d.Sigma, (d.B-d.Mu)/d.Sigma, (x-d.Mu)/d.Sigma
+	// Use the more accurate tail, see Transform
+	if a > 0 {
+		return (stdNormalCDF(-a) - stdNormalCDF(-z)) / (stdNormalCDF(-a) - stdNormalCDF(-b))
+	}
+	return (stdNormalCDF(z) - stdNormalCDF(a)) / (stdNormalCDF(b) - stdNormalCDF(a))
+}
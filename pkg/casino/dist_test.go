@@ -0,0 +1,160 @@
+package casino
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+// Compare a sample histogram against the
+// probability mass predicted by Prob in
+// every bin. The bins cover [a, b] and the
+// mass is integrated with Simpson's rule.
+func helperTestHistogram(dist Distribution, a, b float64, t *testing.T) {
+	const samples = 200000
+	const bins = 40
+	const sub = 64
+
+	sampler := NewSampler(dist, Seed())
+	counts := make([]float64, bins)
+	for i := 0; i < samples; i++ {
+		x := sampler.Sample()
+		if x < a || x >= b {
+			continue
+		}
+		counts[int((x-a)/(b-a)*bins)]++
+	}
+
+	width := (b - a) / bins
+	for i := range counts {
+		// Simpson's rule over the bin
+		lo, h := a+width*float64(i), width/sub
+		mass := dist.Prob(lo) + dist.Prob(lo+width)
+		for j := 1; j < sub; j++ {
+			mass += float64(2+2*(j%2)) * dist.Prob(lo+h*float64(j))
+		}
+		expected := mass * h / 3 * samples
+		// Allow for 5 sigma Poisson fluctuations
+		if math.Abs(counts[i]-expected) > 5*math.Sqrt(expected)+1 {
+			t.Error(fmt.Sprintf("%T%v: bin %v has %v counts, expected %v", dist, dist, i, counts[i], expected))
+		}
+	}
+}
+
+func TestDistributions(t *testing.T) {
+	dists := []Distribution{
+		ExponentialDist{1.5},
+		CauchyDist{1, 0.5},
+		LaplaceDist{-1, 2},
+		StudentTDist{3, 0, 1},
+		StudentTDist{0.5, 2, 3},
+		GammaDist{2.5, 2},
+		GammaDist{1, 1},
+		BetaDist{2, 5},
+		BetaDist{0.5, 0.5},
+		LogNormalDist{0, 0.5},
+		WeibullDist{1.5, 2},
+		TruncatedNormalDist{0, 1, 0, 2},
+		TruncatedNormalDist{0, 1, 5, 6},
+		TruncatedNormalDist{1, 0.5, -8, -6},
+	}
+	ranges := []float64{
+		// Contains a, b pairs sequentially
+		0, 5,
+		-5, 5,
+		-10, 10,
+		-5, 5,
+		-10, 10,
+		0, 5,
+		0, 5,
+		0, 1,
+		0.01, 0.99,
+		0.1, 4,
+		0, 6,
+		0, 2,
+		5, 6,
+		-8, -6,
+	}
+
+	for i := range dists {
+		helperTestHistogram(dists[i], ranges[2*i], ranges[2*i+1], t)
+		// Samples must lie within the support
+		min, max := dists[i].Support()
+		for _, x := range []float64{0, 1e-12, 0.5, 1 - 1e-12} {
+			if y := dists[i].Transform(x); y < min || y > max || math.IsNaN(y) {
+				t.Error(fmt.Sprintf("%T%v: transform of %v is %v, outside support [%v, %v]", dists[i], dists[i], x, y, min, max))
+			}
+		}
+	}
+}
+
+// CDF must be the inverse of Transform,
+// and consistent with Prob.
+func TestCDF(t *testing.T) {
+	linear, err := NewLinearDist(0, 2, -0.48, 0.98)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tabulated, err := NewTabulatedDist(func(x float64) float64 { return math.Exp(-x * x) }, -4, 4, 1e-8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dists := []Distribution{
+		UniDist{},
+		UniDistAB{-1, 3},
+		linear,
+		NormalDist{1, 2},
+		ExponentialDist{1.5},
+		CauchyDist{1, 0.5},
+		LaplaceDist{-1, 2},
+		StudentTDist{3, 0, 1},
+		GammaDist{2.5, 2},
+		BetaDist{2, 5},
+		LogNormalDist{0, 0.5},
+		WeibullDist{1.5, 2},
+		TruncatedNormalDist{0, 1, 0, 2},
+		TruncatedNormalDist{0, 1, 5, 6},
+		tabulated,
+	}
+	for _, dist := range dists {
+		cdf, ok := dist.(CDFer)
+		if !ok {
+			t.Error(fmt.Sprintf("%T%v does not implement CDFer", dist, dist))
+			continue
+		}
+		for _, u := range []float64{1e-6, 0.1, 0.5, 0.75, 0.99} {
+			x := dist.Transform(u)
+			if v := cdf.CDF(x); math.Abs(v-u) > 1e-6 {
+				t.Error(fmt.Sprintf("%T%v: CDF(Transform(%v)) = %v", dist, dist, u, v))
+			}
+			// Derivative of the CDF is the density
+			min, max := dist.Support()
+			h := math.Min(1e-5*math.Max(1, math.Abs(x)), math.Min(x-min, max-x)/2)
+			if p, d := dist.Prob(x), (cdf.CDF(x+h)-cdf.CDF(x-h))/(2*h); math.Abs(p-d) > 1e-4*math.Max(1, p) {
+				t.Error(fmt.Sprintf("%T%v: Prob(%v) = %v, CDF' = %v", dist, dist, x, p, d))
+			}
+		}
+	}
+}
+
+func TestProbEndpoints(t *testing.T) {
+	cases := []struct {
+		dist Distribution
+		x    float64
+		p    float64
+	}{
+		{GammaDist{1, 2}, 0, 2},
+		{GammaDist{2, 1}, 0, 0},
+		{BetaDist{1, 1}, 0, 1},
+		{BetaDist{1, 1}, 1, 1},
+		{BetaDist{2, 1}, 0, 0},
+		{BetaDist{2, 1}, 1, 2},
+		{BetaDist{1, 3}, 0, 3},
+		{BetaDist{1, 3}, 1, 0},
+	}
+	for _, c := range cases {
+		if p := c.dist.Prob(c.x); math.Abs(p-c.p) > 1e-12 {
+			t.Error(fmt.Sprintf("%T%v: Prob(%v) = %v, expected %v", c.dist, c.dist, c.x, p, c.p))
+		}
+	}
+}
@@ -2,6 +2,7 @@ package casino
 
 import (
 	"sync"
+	"time"
 )
 
 // Expectation can be used to find the
@@ -22,13 +23,46 @@ type Expectation struct {
 	// exceeds the number of
 	// desired workers, then
 	// refine will panic.
-	Seeds    []uint64
-	samplers []Sampler
+	Seeds []uint64
+	// Source can be given instead
+	// of Seeds, in which case every
+	// worker obtains its own seed
+	// from it and Refine can run
+	// any number of workers.
+	Source *SeedSource
+	// Generators can be given
+	// instead of Seeds, to supply
+	// every worker with its own
+	// source of samples (e.g. a
+	// Markov chain). If set, the
+	// Distribution is not used.
+	Generators []Generator
+	samplers   []Generator
+	// Batch means for every sampler
+	batches []batchMeans
+
+	// Antithetic evaluates the Function
+	// in pairs at Transform(u) and
+	// Transform(1-u), and averages every
+	// pair into a single trial. This
+	// requires a Distribution.
+	Antithetic bool
+	// Controls are functions with known
+	// expectations ControlMeans, which are
+	// used as control variates. Their
+	// optimal coefficients are estimated
+	// from the samples.
+	Controls     []func(float64) float64
+	ControlMeans []float64
+	// Vector expectation used for
+	// variance reduction
+	vec *ExpectationVec
 
 	// Expectation value (x_bar) and variance (var(x) = m2 / n)
 	x_bar, m2 float64
 	// total number of trials
 	trials int
+	timing timing
 
 	lock sync.RWMutex
 }
@@ -39,14 +73,37 @@ type Expectation struct {
 // the type can be used in it's null
 // value.
 func (exp *Expectation) init() {
+	if exp.vec == nil && (exp.Antithetic || exp.Controls != nil) {
+		exp.initReduction()
+	}
+	if exp.vec != nil {
+		return
+	}
 	if exp.samplers == nil {
-		exp.samplers = make([]Sampler, len(exp.Seeds), len(exp.Seeds))
+		if exp.Generators != nil {
+			exp.samplers = exp.Generators
+			return
+		}
+		exp.samplers = make([]Generator, len(exp.Seeds), len(exp.Seeds))
 		for i := range exp.samplers {
 			exp.samplers[i] = NewSampler(exp, exp.Seeds[i])
 		}
 	}
 }
 
+// grow helper, which creates samplers
+// for additional workers from the Source.
+// Samplers are created in order, so the
+// computation is reproducible.
+func (exp *Expectation) grow(workers int) {
+	if exp.Source == nil || exp.Generators != nil {
+		return
+	}
+	for len(exp.samplers) < workers {
+		exp.samplers = append(exp.samplers, NewSampler(exp, exp.Source.Seed()))
+	}
+}
+
 // Refine will update the expectation
 // and variance estimate. This will update
 // the estimate of expectation and variance
@@ -58,10 +115,19 @@ func (exp *Expectation) Refine(trials, workers int) Result {
 	exp.lock.Lock()
 	defer exp.lock.Unlock()
 	exp.init()
+	if exp.vec != nil {
+		return exp.reduce(exp.vec.Refine(trials, workers))
+	}
+	exp.grow(workers)
+	for len(exp.batches) < len(exp.samplers) {
+		exp.batches = append(exp.batches, batchMeans{})
+	}
+	start := time.Now()
+	exp.timing.grow(len(exp.samplers))
 
 	// Raise panic if insufficient seeds provided
 	if workers > len(exp.samplers) {
-		panic("insufficient seeds provided to run workers")
+		panic("insufficient seeds, source or generators provided to run workers")
 	}
 
 	type valStruct struct {
@@ -84,6 +150,7 @@ func (exp *Expectation) Refine(trials, workers int) Result {
 		// each worker uses a separate sampler
 		go func(sampler int) {
 			defer wait.Done()
+			t0 := time.Now()
 
 			var x_bar_prev float64
 			var x_bar float64
@@ -92,6 +159,7 @@ func (exp *Expectation) Refine(trials, workers int) Result {
 			for n := 1; n <= trials; n++ {
 				// Get a function value, potentially expensive
 				x := exp.Function(exp.samplers[sampler].Sample())
+				exp.batches[sampler].add(x)
 				// Online expectation and variance
 				// updates based on:
 				//
@@ -102,6 +170,7 @@ func (exp *Expectation) Refine(trials, workers int) Result {
 				x_bar = x_bar + (x-x_bar)/float64(n)
 				m2 = m2 + (x-x_bar_prev)*(x-x_bar)
 			}
+			exp.timing.workers[sampler] += time.Since(t0)
 
 			values <- valStruct{x_bar, m2}
 		}(i)
@@ -119,19 +188,32 @@ func (exp *Expectation) Refine(trials, workers int) Result {
 		exp.m2 += v.m2 + delta*delta*float64(exp.trials)*float64(trials)/float64(exp.trials+trials)
 		exp.trials += trials
 	}
+	exp.timing.wall += time.Since(start)
 
 	return exp.result()
 }
 
 func (exp *Expectation) result() Result {
-	return Result{
+	// Generators which discard samples
+	// report them as burned
+	var burn int
+	for _, gen := range exp.samplers {
+		if s, ok := gen.(interface{ Stats() Stats }); ok {
+			burn += s.Stats().Burn
+		}
+	}
+	res := Result{
 		Value: exp.x_bar,
 		// Use unbiased variance estimator
 		Variance: exp.m2 / float64(exp.trials-1),
 		Stats: Stats{
+			Burn:   burn,
 			Trials: exp.trials,
 		},
 	}
+	res.estimateErrors(exp.batches)
+	exp.timing.fill(&res.Stats)
+	return res
 }
 
 // Result returns the current result
@@ -139,5 +221,8 @@ func (exp *Expectation) result() Result {
 func (exp *Expectation) Result() Result {
 	exp.lock.RLock()
 	defer exp.lock.RUnlock()
+	if exp.vec != nil {
+		return exp.reduce(exp.vec.Result())
+	}
 	return exp.result()
 }
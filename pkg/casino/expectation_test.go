@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math"
 	"testing"
+	"time"
 )
 
 const eps_exp = 1e-2
@@ -62,3 +63,87 @@ func TestExpect(t *testing.T) {
 		}
 	}
 }
+
+// Gaussian AR(1) process, which has integrated
+// autocorrelation time (1 + phi) / (1 - phi)
+type ar1 struct {
+	phi, x float64
+	s      Sampler
+}
+
+func (a *ar1) Sample() float64 {
+	a.x = a.phi*a.x + math.Sqrt(1-a.phi*a.phi)*a.s.Sample()
+	return a.x
+}
+
+// Test error estimates for independent and
+// correlated samples
+func TestExpectErrors(t *testing.T) {
+	e := Expectation{
+		Distribution: NormalDist{0, 1},
+		Function:     func(x float64) float64 { return x },
+		Seeds:        Noise(16),
+	}
+	res := e.Refine(10000, 16)
+	if math.Abs(res.StdErr-math.Sqrt(res.Variance/float64(res.Trials))) > 1e-15 {
+		t.Error(fmt.Sprintf("wrong standard error %v", res.StdErr))
+	}
+	if math.Abs(res.Tau-1) > 0.3 || math.Abs(res.ESS/float64(res.Trials)-1) > 0.3 {
+		t.Error(fmt.Sprintf("independent samples should have tau %v ~ 1 (ess %v)", res.Tau, res.ESS))
+	}
+	if lo, hi := res.Interval(0.999); lo > 0 || hi < 0 {
+		t.Error(fmt.Sprintf("interval [%v, %v] should contain 0", lo, hi))
+	}
+
+	const phi = 0.9
+	src := NewSeedSource(Seed())
+	gens := make([]Generator, 16)
+	for i := range gens {
+		gens[i] = &ar1{phi: phi, s: src.Sampler(NormalDist{0, 1})}
+	}
+	e = Expectation{
+		Function:   func(x float64) float64 { return x },
+		Generators: gens,
+	}
+	res = e.Refine(100000, 16)
+	if tau := (1 + phi) / (1 - phi); math.Abs(res.Tau/tau-1) > 0.25 {
+		t.Error(fmt.Sprintf("tau %v is not approximately %v", res.Tau, tau))
+	}
+	if res.BatchStdErr < 3*res.StdErr {
+		t.Error(fmt.Sprintf("batch error %v should exceed naive error %v", res.BatchStdErr, res.StdErr))
+	}
+	lo, hi := res.Interval(0.95)
+	if math.Abs((hi-lo)/(2*1.959964*res.BatchStdErr)-1) > 1e-4 {
+		t.Error(fmt.Sprintf("wrong interval [%v, %v]", lo, hi))
+	}
+}
+
+// Timing is accumulated over refinements.
+func TestExpectTiming(t *testing.T) {
+	exp := Expectation{
+		Distribution: UniDist{},
+		Function: func(x float64) float64 {
+			time.Sleep(10 * time.Microsecond)
+			return x
+		},
+		Seeds: Noise(4),
+	}
+	exp.Refine(100, 4)
+	res := exp.Refine(100, 2)
+	if len(res.BusyTime) != 4 || res.Time <= 0 {
+		t.Fatal(fmt.Sprintf("missing timing: %v, %v", res.Time, res.BusyTime))
+	}
+	// Samplers 0, 1 ran twice as long
+	if res.BusyTime[0] < res.BusyTime[3] || res.BusyTime[3] < time.Millisecond {
+		t.Error(fmt.Sprintf("wrong busy times %v", res.BusyTime))
+	}
+	if busy := res.TotalBusyTime(); busy < res.Time || busy > 4*res.Time {
+		t.Error(fmt.Sprintf("busy time %v does not match wall time %v", busy, res.Time))
+	}
+	if rate := float64(res.Trials) / res.Time.Seconds(); math.Abs(res.Rate-rate) > 1e-9*rate {
+		t.Error(fmt.Sprintf("rate %v should be %v", res.Rate, rate))
+	}
+	if r := exp.Result(); r.Time != res.Time {
+		t.Error("result should report the same time")
+	}
+}
@@ -0,0 +1,263 @@
+package casino
+
+import (
+	"sync"
+	"time"
+
+	"gonum.org/v1/gonum/diff/fd"
+	"gonum.org/v1/gonum/mat"
+)
+
+// ExpectationVec can be used to find the
+// expectation of a vector valued function,
+// together with the covariance of its
+// components. See Expectation.
+type ExpectationVec struct {
+	// The distribution used to
+	// sample from
+	Distribution
+	// Function to be averaged, which
+	// writes Dim values into dst
+	Function func(x float64, dst []float64)
+	Dim      int
+	// Seeds, Source and Generators
+	// supply the workers, as for
+	// Expectation
+	Seeds      []uint64
+	Source     *SeedSource
+	Generators []Generator
+	samplers   []Generator
+	// Batch means for every sampler
+	// and component
+	batches [][]batchMeans
+
+	// Expectation value and co-moments (cov = m2 / n)
+	x_bar, m2 []float64
+	// total number of trials
+	trials int
+	timing timing
+
+	lock sync.RWMutex
+}
+
+// ResultVec contains a vector result
+// and the covariance of its components.
+type ResultVec struct {
+	Value []float64
+	// Covariance of the function's components
+	// (not of the estimate, which is smaller by
+	// a factor of Trials for independent samples)
+	Covariance *mat.SymDense
+	Stats
+
+	batches [][]batchMeans
+}
+
+// init helper, see Expectation.init
+func (exp *ExpectationVec) init() {
+	if exp.x_bar == nil {
+		exp.x_bar = make([]float64, exp.Dim)
+		exp.m2 = make([]float64, exp.Dim*exp.Dim)
+	}
+	if exp.samplers == nil {
+		if exp.Generators != nil {
+			exp.samplers = exp.Generators
+			return
+		}
+		exp.samplers = make([]Generator, len(exp.Seeds), len(exp.Seeds))
+		for i := range exp.samplers {
+			exp.samplers[i] = NewSampler(exp, exp.Seeds[i])
+		}
+	}
+}
+
+// grow helper, see Expectation.grow
+func (exp *ExpectationVec) grow(workers int) {
+	if exp.Source != nil && exp.Generators == nil {
+		for len(exp.samplers) < workers {
+			exp.samplers = append(exp.samplers, NewSampler(exp, exp.Source.Seed()))
+		}
+	}
+	for len(exp.batches) < len(exp.samplers) {
+		exp.batches = append(exp.batches, make([]batchMeans, exp.Dim))
+	}
+}
+
+// Refine will update the expectation and
+// covariance estimate, see Expectation.Refine.
+// This calls Function trials * workers times.
+func (exp *ExpectationVec) Refine(trials, workers int) ResultVec {
+	exp.lock.Lock()
+	defer exp.lock.Unlock()
+	exp.init()
+	exp.grow(workers)
+
+	if workers > len(exp.samplers) {
+		panic("insufficient seeds, source or generators provided to run workers")
+	}
+	start := time.Now()
+	exp.timing.grow(len(exp.samplers))
+
+	type valStruct struct {
+		x_bar, m2 []float64
+	}
+
+	values := make(chan valStruct)
+	wait := sync.WaitGroup{}
+
+	wait.Add(workers)
+	go func() {
+		wait.Wait()
+		close(values)
+	}()
+
+	dim := exp.Dim
+	for i := 0; i < workers; i++ {
+		go func(sampler int) {
+			defer wait.Done()
+			t0 := time.Now()
+
+			x := make([]float64, dim)
+			delta := make([]float64, dim)
+			x_bar := make([]float64, dim)
+			m2 := make([]float64, dim*dim)
+
+			for n := 1; n <= trials; n++ {
+				exp.Function(exp.samplers[sampler].Sample(), x)
+				// Welford's update, extended to co-moments:
+				//
+				//     m2 += (x - x_bar_prev) (x - x_bar)^T
+				//
+				for j := range x {
+					exp.batches[sampler][j].add(x[j])
+					delta[j] = x[j] - x_bar[j]
+					x_bar[j] += delta[j] / float64(n)
+				}
+				for j := range x {
+					for k := range x {
+						m2[j*dim+k] += delta[j] * (x[k] - x_bar[k])
+					}
+				}
+			}
+
+			exp.timing.workers[sampler] += time.Since(t0)
+
+			values <- valStruct{x_bar, m2}
+		}(i)
+	}
+
+	// Combine as in Expectation.Refine (Chan et al.)
+	for v := range values {
+		f := float64(exp.trials) * float64(trials) / float64(exp.trials+trials)
+		delta := make([]float64, dim)
+		for j := range delta {
+			delta[j] = v.x_bar[j] - exp.x_bar[j]
+			exp.x_bar[j] += delta[j] * float64(trials) / float64(exp.trials+trials)
+		}
+		for j := range delta {
+			for k := range delta {
+				exp.m2[j*dim+k] += v.m2[j*dim+k] + delta[j]*delta[k]*f
+			}
+		}
+		exp.trials += trials
+	}
+	exp.timing.wall += time.Since(start)
+
+	return exp.result()
+}
+
+func (exp *ExpectationVec) result() ResultVec {
+	var burn int
+	for _, gen := range exp.samplers {
+		if s, ok := gen.(interface{ Stats() Stats }); ok {
+			burn += s.Stats().Burn
+		}
+	}
+
+	res := ResultVec{
+		Value:      make([]float64, exp.Dim),
+		Covariance: mat.NewSymDense(exp.Dim, nil),
+		Stats: Stats{
+			Burn:   burn,
+			Trials: exp.trials,
+		},
+		batches: make([][]batchMeans, len(exp.batches)),
+	}
+	copy(res.Value, exp.x_bar)
+	for j := 0; j < exp.Dim && exp.m2 != nil; j++ {
+		for k := j; k < exp.Dim; k++ {
+			// Use unbiased estimator, the
+			// co-moments are symmetric up
+			// to rounding
+			res.Covariance.SetSym(j, k, exp.m2[j*exp.Dim+k]/float64(exp.trials-1))
+		}
+	}
+	for i, bs := range exp.batches {
+		res.batches[i] = make([]batchMeans, len(bs))
+		for j, b := range bs {
+			b.sums = append([]float64(nil), b.sums...)
+			res.batches[i][j] = b
+		}
+	}
+	exp.timing.fill(&res.Stats)
+	return res
+}
+
+// Result returns the current result
+// of the computation.
+func (exp *ExpectationVec) Result() ResultVec {
+	exp.lock.RLock()
+	defer exp.lock.RUnlock()
+	return exp.result()
+}
+
+// Linear returns the estimate of g . Value,
+// including its errors.
+func (r ResultVec) Linear(g []float64) Result {
+	res := Result{Stats: r.Stats}
+	for j := range g {
+		res.Value += g[j] * r.Value[j]
+	}
+	res.Variance = mat.Inner(mat.NewVecDense(len(g), g), r.Covariance, mat.NewVecDense(len(g), g))
+
+	// All components are added to their batches
+	// together, so the batches line up
+	batches := make([]batchMeans, len(r.batches))
+	for i, bs := range r.batches {
+		if len(bs) == 0 {
+			continue
+		}
+		batches[i] = batchMeans{size: bs[0].size, sums: make([]float64, len(bs[0].sums))}
+		for j := range bs {
+			for k, s := range bs[j].sums {
+				batches[i].sums[k] += g[j] * s
+			}
+		}
+	}
+	res.estimateErrors(batches)
+	return res
+}
+
+// Component returns the estimate of
+// the i-th component.
+func (r ResultVec) Component(i int) Result {
+	g := make([]float64, len(r.Value))
+	g[i] = 1
+	return r.Linear(g)
+}
+
+// Derived returns the estimate of fn(Value),
+// with errors propagated to first order (i.e.
+// using the delta method). If grad is nil, the
+// gradient of fn is computed numerically.
+func (r ResultVec) Derived(fn func([]float64) float64, grad func(x, grad []float64)) Result {
+	g := make([]float64, len(r.Value))
+	if grad != nil {
+		grad(r.Value, g)
+	} else {
+		fd.Gradient(g, fn, r.Value, nil)
+	}
+	res := r.Linear(g)
+	res.Value = fn(r.Value)
+	return res
+}
@@ -0,0 +1,76 @@
+package casino
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestExpectVec(t *testing.T) {
+	// Moments of the uniform distribution
+	moments := func(x float64, dst []float64) {
+		dst[0], dst[1] = x, x*x
+	}
+	e := ExpectationVec{
+		Distribution: UniDist{},
+		Function:     moments,
+		Dim:          2,
+		Seeds:        Noise(64),
+	}
+	e.Refine(1000, 32)
+	res := e.Refine(1000, 64)
+	if res.Trials != 96000 {
+		t.Error(fmt.Sprintf("wrong number of trials %v", res.Trials))
+	}
+
+	exps := []float64{1.0 / 2.0, 1.0 / 3.0}
+	covs := [][]float64{
+		{1.0 / 12.0, 1.0 / 12.0},
+		{1.0 / 12.0, 4.0 / 45.0},
+	}
+	for j := range exps {
+		if math.Abs(res.Value[j]-exps[j]) > eps_exp {
+			t.Error(fmt.Sprintf("(value) %v is not approximately %v", res.Value[j], exps[j]))
+		}
+		for k := range exps {
+			if math.Abs(res.Covariance.At(j, k)-covs[j][k]) > 1e-2 {
+				t.Error(fmt.Sprintf("(covariance) %v is not approximately %v", res.Covariance.At(j, k), covs[j][k]))
+			}
+		}
+	}
+
+	// Components match a scalar expectation
+	s := Expectation{
+		Distribution: UniDist{},
+		Function:     func(x float64) float64 { return x * x },
+		Seeds:        e.Seeds,
+	}
+	s.Refine(1000, 32)
+	scalar, comp := s.Refine(1000, 64), res.Component(1)
+	if math.Abs(scalar.Value-comp.Value) > 1e-12 || math.Abs(scalar.Variance-comp.Variance) > 1e-12 {
+		t.Error(fmt.Sprintf("component %v does not match scalar result %v", comp, scalar))
+	}
+	if math.Abs(scalar.BatchStdErr-comp.BatchStdErr) > 1e-12 {
+		t.Error(fmt.Sprintf("batch error %v does not match %v", comp.BatchStdErr, scalar.BatchStdErr))
+	}
+
+	// Derived quantities get propagated errors
+	e = ExpectationVec{
+		Distribution: NormalDist{1, 2},
+		Function:     moments,
+		Dim:          2,
+		Source:       NewSeedSource(Seed()),
+	}
+	variance := func(v []float64) float64 { return v[1] - v[0]*v[0] }
+	grad := func(v, g []float64) { g[0], g[1] = -2*v[0], 1 }
+	res = e.Refine(10000, 16)
+	for _, der := range []Result{res.Derived(variance, grad), res.Derived(variance, nil)} {
+		if math.Abs(der.Value-4) > 0.1 {
+			t.Error(fmt.Sprintf("(value) %v is not approximately 4", der.Value))
+		}
+		// Variance of (x - mu)^2 is 2 sigma^4
+		if math.Abs(der.Variance/32-1) > 0.1 {
+			t.Error(fmt.Sprintf("(variance) %v is not approximately 32", der.Variance))
+		}
+	}
+}
@@ -0,0 +1,302 @@
+package casino
+
+import (
+	"errors"
+	"math"
+	"sort"
+
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// Sub-intervals used to integrate a density
+// over a bin with Simpson's rule
+const histogramSimpson = 16
+
+// Histogram accumulates weighted entries into
+// bins [e_i, e_{i+1}), where the last bin also
+// contains its upper edge. Entries outside of
+// the bins are counted as under- or overflow.
+//
+// A Histogram is not safe for concurrent use,
+// every worker should fill its own histogram,
+// which can then be combined using Merge.
+type Histogram struct {
+	edges []float64
+	// Sum of weights and squared weights
+	// for every bin
+	weights, weights2   []float64
+	underflow, overflow float64
+	entries             int
+	uniform             bool
+}
+
+// NewHistogram returns a histogram with the given
+// number of bins of equal width, covering [a, b].
+func NewHistogram(a, b float64, bins int) (*Histogram, error) {
+	if !(a < b) || math.IsInf(a, 0) || math.IsInf(b, 0) {
+		return nil, errors.New("invalid range")
+	}
+	if bins < 1 {
+		return nil, errors.New("need at least one bin")
+	}
+	edges := make([]float64, bins+1)
+	for i := range edges {
+		edges[i] = a + (b-a)*float64(i)/float64(bins)
+	}
+	hist, _ := NewHistogramEdges(edges)
+	hist.uniform = true
+	return hist, nil
+}
+
+// NewHistogramEdges returns a histogram with the
+// given bin edges, which must be finite and strictly
+// increasing.
+func NewHistogramEdges(edges []float64) (*Histogram, error) {
+	if len(edges) < 2 {
+		return nil, errors.New("need at least one bin")
+	}
+	for i, e := range edges {
+		if math.IsInf(e, 0) || math.IsNaN(e) {
+			return nil, errors.New("edges must be finite")
+		}
+		if i > 0 && !(edges[i-1] < e) {
+			return nil, errors.New("edges must be strictly increasing")
+		}
+	}
+	hist := &Histogram{
+		edges:    make([]float64, len(edges)),
+		weights:  make([]float64, len(edges)-1),
+		weights2: make([]float64, len(edges)-1),
+	}
+	copy(hist.edges, edges)
+	return hist, nil
+}
+
+// NewAdaptiveHistogram returns a histogram with
+// bins of (approximately) equal counts, whose edges
+// are the empirical quantiles of the samples. The
+// samples are added to the histogram.
+func NewAdaptiveHistogram(samples []float64, bins int) (*Histogram, error) {
+	if bins < 1 {
+		return nil, errors.New("need at least one bin")
+	}
+	if len(samples) < 2 {
+		return nil, errors.New("need at least two samples")
+	}
+	sorted := make([]float64, len(samples))
+	copy(sorted, samples)
+	sort.Float64s(sorted)
+
+	// Repeated samples can collapse bins
+	edges := []float64{sorted[0]}
+	for i := 1; i <= bins; i++ {
+		e := sorted[(len(sorted)-1)*i/bins]
+		if e > edges[len(edges)-1] {
+			edges = append(edges, e)
+		}
+	}
+	hist, err := NewHistogramEdges(edges)
+	if err != nil {
+		return nil, err
+	}
+	for _, x := range sorted {
+		hist.Add(x)
+	}
+	return hist, nil
+}
+
+// Bins returns the number of bins.
+func (h *Histogram) Bins() int {
+	return len(h.weights)
+}
+
+// Edges returns the bin edges.
+func (h *Histogram) Edges() []float64 {
+	edges := make([]float64, len(h.edges))
+	copy(edges, h.edges)
+	return edges
+}
+
+// Find the bin containing x, returns -1 for
+// underflow and Bins() for overflow.
+func (h *Histogram) bin(x float64) int {
+	last := len(h.edges) - 1
+	switch {
+	case x < h.edges[0]:
+		return -1
+	case x > h.edges[last]:
+		return last
+	case x == h.edges[last]:
+		return last - 1
+	case h.uniform:
+		i := int((x - h.edges[0]) / (h.edges[last] - h.edges[0]) * float64(last))
+		// Guard against rounding at the edges
+		if i > 0 && x < h.edges[i] {
+			i--
+		} else if i < last-1 && x >= h.edges[i+1] {
+			i++
+		}
+		return i
+	}
+	return sort.Search(last, func(i int) bool { return h.edges[i+1] > x })
+}
+
+// Add adds an entry with unit weight.
+func (h *Histogram) Add(x float64) {
+	h.AddWeighted(x, 1)
+}
+
+// AddWeighted adds an entry with weight w.
+// NaN entries are counted as overflow.
+func (h *Histogram) AddWeighted(x, w float64) {
+	h.entries++
+	if math.IsNaN(x) {
+		h.overflow += w
+		return
+	}
+	switch i := h.bin(x); {
+	case i < 0:
+		h.underflow += w
+	case i >= len(h.weights):
+		h.overflow += w
+	default:
+		h.weights[i] += w
+		h.weights2[i] += w * w
+	}
+}
+
+// Merge adds the entries of other, which
+// must have the same edges.
+func (h *Histogram) Merge(other *Histogram) error {
+	if len(h.edges) != len(other.edges) {
+		return errors.New("histograms need to have the same edges")
+	}
+	for i := range h.edges {
+		if h.edges[i] != other.edges[i] {
+			return errors.New("histograms need to have the same edges")
+		}
+	}
+	for i := range h.weights {
+		h.weights[i] += other.weights[i]
+		h.weights2[i] += other.weights2[i]
+	}
+	h.underflow += other.underflow
+	h.overflow += other.overflow
+	h.entries += other.entries
+	return nil
+}
+
+// Entries returns the number of entries,
+// including under- and overflow.
+func (h *Histogram) Entries() int {
+	return h.entries
+}
+
+// Total returns the total weight of all
+// entries, including under- and overflow.
+func (h *Histogram) Total() float64 {
+	return h.underflow + h.InRange() + h.overflow
+}
+
+// InRange returns the total weight within
+// the bins.
+func (h *Histogram) InRange() float64 {
+	var w float64
+	for _, v := range h.weights {
+		w += v
+	}
+	return w
+}
+
+// Underflow and Overflow return the weight
+// of entries below and above the bins.
+func (h *Histogram) Underflow() float64 {
+	return h.underflow
+}
+
+func (h *Histogram) Overflow() float64 {
+	return h.overflow
+}
+
+// Count returns the weight in bin i and its
+// Poisson error, i.e. the square root of the
+// sum of squared weights.
+func (h *Histogram) Count(i int) (float64, float64) {
+	return h.weights[i], math.Sqrt(h.weights2[i])
+}
+
+// Density returns the density in bin i and its
+// error, normalized such that the density of all
+// entries (including under- and overflow) integrates
+// to one. This is directly comparable to the
+// probability density of the entries.
+func (h *Histogram) Density(i int) (float64, float64) {
+	w, err := h.Count(i)
+	norm := h.Total() * (h.edges[i+1] - h.edges[i])
+	if norm == 0 {
+		return 0, 0
+	}
+	return w / norm, err / norm
+}
+
+// Scale returns the factor converting a probability
+// density into the expected count of bin i, i.e.
+// the total weight times the bin width.
+func (h *Histogram) Scale(i int) float64 {
+	return h.Total() * (h.edges[i+1] - h.edges[i])
+}
+
+// ChiSquare compares the histogram with the density
+// p, which does not need to be normalized. The expected
+// counts are the integrals of p over the bins, scaled to
+// the weight within the bins. Returns the statistic, the
+// degrees of freedom and the p-value.
+//
+// For weighted entries the variance of every bin is
+// scaled by the ratio of the sums of squared weights
+// and weights. Bins with vanishing expectation are
+// skipped, unless they contain entries.
+func (h *Histogram) ChiSquare(p Prober) (float64, int, float64) {
+	mass := make([]float64, len(h.weights))
+	var total float64
+	for i := range mass {
+		lo, width := h.edges[i], h.edges[i+1]-h.edges[i]
+		step := width / histogramSimpson
+		m := p.Prob(lo) + p.Prob(lo+width)
+		for j := 1; j < histogramSimpson; j++ {
+			m += float64(2+2*(j%2)) * p.Prob(lo+step*float64(j))
+		}
+		mass[i] = m * step / 3
+		total += mass[i]
+	}
+
+	var w, w2 float64
+	for i := range h.weights {
+		w += h.weights[i]
+		w2 += h.weights2[i]
+	}
+	if w == 0 || total == 0 {
+		return 0, 0, 1
+	}
+	ratio := w2 / w
+
+	var chi2 float64
+	var bins int
+	for i := range mass {
+		expected := w * mass[i] / total
+		if expected <= 0 {
+			if h.weights[i] != 0 {
+				return math.Inf(+1), len(mass) - 1, 0
+			}
+			continue
+		}
+		d := h.weights[i] - expected
+		chi2 += d * d / (expected * ratio)
+		bins++
+	}
+	dof := bins - 1
+	if dof < 1 {
+		return chi2, dof, 1
+	}
+	return chi2, dof, distuv.ChiSquared{K: float64(dof)}.Survival(chi2)
+}
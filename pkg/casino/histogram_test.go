@@ -0,0 +1,91 @@
+package casino
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestHistogram(t *testing.T) {
+	if _, err := NewHistogram(1, 0, 10); err == nil {
+		t.Error("should reject invalid range")
+	}
+	if _, err := NewHistogramEdges([]float64{0, 1, 1}); err == nil {
+		t.Error("should reject repeated edges")
+	}
+
+	// Binning at the edges
+	hist, err := NewHistogram(0, 1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, x := range []float64{-1, 0, 0.1, 0.3, 0.99, 1, 2, math.NaN()} {
+		hist.Add(x)
+	}
+	counts := []float64{1, 1, 0, 1, 0, 0, 0, 0, 0, 2}
+	for i := range counts {
+		if c, _ := hist.Count(i); c != counts[i] {
+			t.Error(fmt.Sprintf("bin %v has %v entries, should have %v", i, c, counts[i]))
+		}
+	}
+	if hist.Underflow() != 1 || hist.Overflow() != 2 || hist.Entries() != 8 || hist.Total() != 8 {
+		t.Error(fmt.Sprintf("wrong totals %v, %v, %v, %v", hist.Underflow(), hist.Overflow(), hist.Entries(), hist.Total()))
+	}
+
+	// Merged worker histograms, weighted entries
+	dist := NormalDist{1, 1}
+	hist, _ = NewHistogram(-2, 4, 30)
+	for w := 0; w < 4; w++ {
+		worker, _ := NewHistogram(-2, 4, 30)
+		s := NewSampler(UniDistAB{-3, 5}, Seed())
+		for i := 0; i < 50000; i++ {
+			x := s.Sample()
+			worker.AddWeighted(x, 8*dist.Prob(x))
+		}
+		if err := hist.Merge(worker); err != nil {
+			t.Fatal(err)
+		}
+	}
+	other, _ := NewHistogram(-2, 4, 31)
+	if err := hist.Merge(other); err == nil {
+		t.Error("should reject different edges")
+	}
+	if math.Abs(hist.Total()-200000) > 2000 {
+		t.Error(fmt.Sprintf("total weight %v should be 200000", hist.Total()))
+	}
+	for i := 0; i < hist.Bins(); i++ {
+		x := (hist.edges[i] + hist.edges[i+1]) / 2
+		d, err := hist.Density(i)
+		if math.Abs(d-dist.Prob(x)) > 5*err+1e-3 {
+			t.Error(fmt.Sprintf("density %v +- %v in bin %v should be %v", d, err, i, dist.Prob(x)))
+		}
+	}
+	if _, _, p := hist.ChiSquare(dist); p < 1e-4 {
+		t.Error(fmt.Sprintf("chi-squared p-value %v is too small", p))
+	}
+	if _, _, p := hist.ChiSquare(NormalDist{1.05, 1}); p > 1e-4 {
+		t.Error(fmt.Sprintf("chi-squared p-value %v is too large", p))
+	}
+
+	// Adaptive bins have equal counts
+	s := NewSampler(ExponentialDist{1}, Seed())
+	samples := make([]float64, 10000)
+	for i := range samples {
+		samples[i] = s.Sample()
+	}
+	hist, err = NewAdaptiveHistogram(samples, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hist.Bins() != 20 || hist.Total() != 10000 || hist.InRange() != 10000 {
+		t.Error(fmt.Sprintf("adaptive histogram has %v bins, %v entries", hist.Bins(), hist.Total()))
+	}
+	for i := 0; i < hist.Bins(); i++ {
+		if c, _ := hist.Count(i); math.Abs(c-500) > 2 {
+			t.Error(fmt.Sprintf("adaptive bin %v has %v entries", i, c))
+		}
+	}
+	if _, _, p := hist.ChiSquare(ExponentialDist{1}); p < 1e-4 {
+		t.Error(fmt.Sprintf("chi-squared p-value %v is too small", p))
+	}
+}
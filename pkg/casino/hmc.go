@@ -0,0 +1,267 @@
+package casino
+
+import (
+	"fmt"
+	"math"
+
+	"golang.org/x/exp/rand"
+)
+
+// Defaults for HMC chains
+const defaultHMCStep = 0.1
+const defaultHMCLeapfrog = 10
+const defaultHMCAcceptance = 0.8
+
+// Energy error above which a trajectory
+// is considered divergent.
+const hmcDivergence = 1000
+
+// HMCStats holds statistics on a Hamiltonian
+// Monte Carlo chain.
+type HMCStats struct {
+	Stats
+	// Number of divergent trajectories
+	// after warm up
+	Divergences int
+	// Average acceptance probability
+	// after warm up
+	MeanAcceptance float64
+	// Step size used after warm up
+	Step float64
+}
+
+func (s HMCStats) String() string {
+	return fmt.Sprintf("%v transitions (%v burned), acceptance %v (mean probability %v), step %v, %v divergences",
+		s.Trials, s.Burn, s.AcceptanceRate(), s.MeanAcceptance, s.Step, s.Divergences)
+}
+
+// HMC implements Hamiltonian Monte Carlo for
+// targets over R^n, as presented in:
+//
+//     Neal, R. M. (2011). "MCMC Using Hamiltonian Dynamics". Handbook of
+//     Markov Chain Monte Carlo. Chapman & Hall/CRC: 113–162.
+//
+// Every trajectory takes Leapfrog steps of size
+// Step with the leapfrog integrator. During the
+// Warmup, the step size is adapted with the dual
+// averaging scheme from:
+//
+//     Hoffman, M. D.; Gelman, A. (2014). "The No-U-Turn Sampler: Adaptively
+//     Setting Path Lengths in Hamiltonian Monte Carlo". JMLR 15: 1593–1623.
+//
+// If AdaptMass is set, a diagonal mass matrix is
+// estimated from the first half of the warm up,
+// after which the step size adaptation restarts.
+//
+// The dimension is given by len(Start). The type can
+// be used in it's null value, once LogPi, Grad and
+// Start are set. Defaults are resolved when the chain
+// starts and are not written back to the exported
+// fields. A chain is not safe for concurrent use.
+type HMC struct {
+	// Log of the (un-normalized) target density
+	// and its gradient, which is written into grad
+	LogPi func(x []float64) float64
+	Grad  func(x, grad []float64)
+	// Initial state of the chain
+	Start []float64
+	// Initial step size (defaults to 0.1) and
+	// number of leapfrog steps per trajectory
+	// (defaults to 10)
+	Step     float64
+	Leapfrog int
+	// Number of warm up transitions, which are
+	// used to adapt the step size and discarded
+	Warmup int
+	// Acceptance probability targeted during
+	// warm up (defaults to 0.8)
+	Acceptance float64
+	// Estimate a diagonal mass matrix
+	AdaptMass bool
+	// Seed for the chain's random numbers
+	Seed uint64
+
+	rng           *rand.Rand
+	x, grad       []float64
+	logPi         float64
+	invMass       []float64
+	step          float64
+	leapfrog      int
+	acceptance    float64
+	warm          bool
+	stats         HMCStats
+	sumAcceptance float64
+	p, xNew, gNew []float64
+}
+
+// init helper, see Expectation.init
+func (h *HMC) init() {
+	if h.rng != nil {
+		return
+	}
+	n := len(h.Start)
+	h.rng = rand.New(rand.NewSource(h.Seed))
+	h.x = make([]float64, n)
+	copy(h.x, h.Start)
+	h.grad = make([]float64, n)
+	h.Grad(h.x, h.grad)
+	h.logPi = h.LogPi(h.x)
+	h.invMass = make([]float64, n)
+	for i := range h.invMass {
+		h.invMass[i] = 1
+	}
+	h.p, h.xNew, h.gNew = make([]float64, n), make([]float64, n), make([]float64, n)
+	h.step = h.Step
+	if h.step <= 0 {
+		h.step = defaultHMCStep
+	}
+	h.leapfrog, h.acceptance = h.Leapfrog, h.Acceptance
+	if h.leapfrog < 1 {
+		h.leapfrog = defaultHMCLeapfrog
+	}
+	if h.acceptance <= 0 || h.acceptance >= 1 {
+		h.acceptance = defaultHMCAcceptance
+	}
+}
+
+// Run one trajectory with step size eps.
+// Returns the acceptance probability and if
+// the trajectory diverged.
+func (h *HMC) transition(eps float64) (float64, bool) {
+	// Draw momentum p ~ N(0, M)
+	var kinetic float64
+	for i := range h.p {
+		h.p[i] = h.rng.NormFloat64() / math.Sqrt(h.invMass[i])
+		kinetic += 0.5 * h.p[i] * h.p[i] * h.invMass[i]
+	}
+	energy := kinetic - h.logPi
+
+	// Leapfrog integration
+	copy(h.xNew, h.x)
+	copy(h.gNew, h.grad)
+	for l := 0; l < h.leapfrog; l++ {
+		for i := range h.p {
+			h.p[i] += 0.5 * eps * h.gNew[i]
+			h.xNew[i] += eps * h.invMass[i] * h.p[i]
+		}
+		h.Grad(h.xNew, h.gNew)
+		for i := range h.p {
+			h.p[i] += 0.5 * eps * h.gNew[i]
+		}
+	}
+
+	logPi := h.LogPi(h.xNew)
+	kinetic = 0
+	for i := range h.p {
+		kinetic += 0.5 * h.p[i] * h.p[i] * h.invMass[i]
+	}
+	delta := kinetic - logPi - energy
+	if math.IsNaN(delta) || delta > hmcDivergence {
+		return 0, true
+	}
+
+	accept := math.Min(1, math.Exp(-delta))
+	if h.rng.Float64() < accept {
+		h.x, h.xNew = h.xNew, h.x
+		h.grad, h.gNew = h.gNew, h.grad
+		h.logPi = logPi
+		h.stats.Accepted++
+	}
+	return accept, false
+}
+
+// Adapt the step size using dual averaging over
+// n transitions.
+func (h *HMC) adaptStep(n int, collect func([]float64)) {
+	const gamma, t0, kappa = 0.05, 10, 0.75
+	mu := math.Log(10 * h.step)
+	var hBar, logEpsBar float64
+	for m := 1; m <= n; m++ {
+		accept, _ := h.transition(h.step)
+		if collect != nil {
+			collect(h.x)
+		}
+		fm := float64(m)
+		hBar = (1-1/(fm+t0))*hBar + (h.acceptance-accept)/(fm+t0)
+		logEps := mu - math.Sqrt(fm)/gamma*hBar
+		eta := math.Pow(fm, -kappa)
+		logEpsBar = eta*logEps + (1-eta)*logEpsBar
+		h.step = math.Exp(logEps)
+	}
+	if n > 0 {
+		h.step = math.Exp(logEpsBar)
+	}
+}
+
+// Run warm up, adapting step size and mass.
+func (h *HMC) warmup() {
+	if h.AdaptMass && h.Warmup >= 20 {
+		// Estimate variance in first half (Welford)
+		half := h.Warmup / 2
+		mean := make([]float64, len(h.x))
+		m2 := make([]float64, len(h.x))
+		count := 0
+		h.adaptStep(half, func(x []float64) {
+			count++
+			for i := range x {
+				delta := x[i] - mean[i]
+				mean[i] += delta / float64(count)
+				m2[i] += delta * (x[i] - mean[i])
+			}
+		})
+		// Regularize towards unit mass, as done by Stan
+		n := float64(count)
+		for i := range h.invMass {
+			h.invMass[i] = n/(n+5)*m2[i]/(n-1) + 1e-3*5/(n+5)
+		}
+		h.adaptStep(h.Warmup-half, nil)
+	} else {
+		h.adaptStep(h.Warmup, nil)
+	}
+	h.stats.Burn = h.Warmup
+	h.stats.Accepted = 0
+	h.stats.Step = h.step
+	h.warm = true
+}
+
+// SampleInto advances the chain by one transition
+// and writes the new state into dst.
+func (h *HMC) SampleInto(dst []float64) {
+	h.init()
+	if !h.warm {
+		h.warmup()
+	}
+	accept, divergent := h.transition(h.step)
+	h.stats.Trials++
+	if divergent {
+		h.stats.Divergences++
+	}
+	h.sumAcceptance += accept
+	h.stats.MeanAcceptance = h.sumAcceptance / float64(h.stats.Trials)
+	copy(dst, h.x)
+}
+
+// Dim returns the dimension of the chain.
+func (h *HMC) Dim() int {
+	return len(h.Start)
+}
+
+// StepSize returns the current step size.
+func (h *HMC) StepSize() float64 {
+	h.init()
+	return h.step
+}
+
+// InvMass returns the diagonal of the
+// inverse mass matrix.
+func (h *HMC) InvMass() []float64 {
+	h.init()
+	res := make([]float64, len(h.invMass))
+	copy(res, h.invMass)
+	return res
+}
+
+// Stats returns statistics on the chain.
+func (h *HMC) Stats() HMCStats {
+	return h.stats
+}
@@ -0,0 +1,84 @@
+package casino
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestHMC(t *testing.T) {
+	// Independent Gaussians with very different scales
+	sigmas := []float64{1, 10, 0.1}
+	logPi := func(x []float64) float64 {
+		var l float64
+		for i := range x {
+			l -= x[i] * x[i] / (2 * sigmas[i] * sigmas[i])
+		}
+		return l
+	}
+	grad := func(x, g []float64) {
+		for i := range x {
+			g[i] = -x[i] / (sigmas[i] * sigmas[i])
+		}
+	}
+
+	h := HMC{
+		LogPi:     logPi,
+		Grad:      grad,
+		Start:     []float64{1, 1, 1},
+		Warmup:    2000,
+		AdaptMass: true,
+		Seed:      42,
+	}
+
+	const n = 20000
+	x := make([]float64, 3)
+	mean, sq := make([]float64, 3), make([]float64, 3)
+	for i := 0; i < n; i++ {
+		h.SampleInto(x)
+		for j := range x {
+			mean[j] += x[j] / n
+			sq[j] += x[j] * x[j] / n
+		}
+	}
+	for j := range sigmas {
+		if math.Abs(mean[j]) > 0.1*sigmas[j] {
+			t.Error(fmt.Sprintf("mean %v is not approximately 0", mean[j]))
+		}
+		if math.Abs(sq[j]/(sigmas[j]*sigmas[j])-1) > 0.15 {
+			t.Error(fmt.Sprintf("variance %v is not approximately %v", sq[j], sigmas[j]*sigmas[j]))
+		}
+	}
+
+	stats := h.Stats()
+	if stats.Trials != n || stats.Burn != 2000 {
+		t.Error(fmt.Sprintf("wrong number of transitions recorded: %v", stats))
+	}
+	if math.Abs(stats.MeanAcceptance-defaultHMCAcceptance) > 0.15 {
+		t.Error(fmt.Sprintf("acceptance not adapted: %v", stats))
+	}
+	if stats.Divergences != 0 {
+		t.Error(fmt.Sprintf("unexpected divergences: %v", stats))
+	}
+	if mass := h.InvMass(); mass[1]/mass[2] < 1e3 {
+		t.Error(fmt.Sprintf("mass matrix %v not adapted to scales", mass))
+	}
+	if h.Step != 0 || h.Leapfrog != 0 || h.Acceptance != 0 {
+		t.Error(fmt.Sprintf("configuration was changed: %v, %v, %v", h.Step, h.Leapfrog, h.Acceptance))
+	}
+
+	// Far too large steps diverge
+	h = HMC{
+		LogPi: logPi,
+		Grad:  grad,
+		Start: []float64{1, 1, 1},
+		Step:  10,
+		Seed:  Seed(),
+	}
+	for i := 0; i < 100; i++ {
+		h.SampleInto(x)
+	}
+	if h.Stats().Divergences != 100 {
+		t.Error(fmt.Sprintf("should diverge: %v", h.Stats()))
+	}
+}
@@ -0,0 +1,342 @@
+package casino
+
+import (
+	"errors"
+	"math"
+	"sort"
+
+	"github.com/dyedgreen/comp-phys/pkg/signal"
+	"gonum.org/v1/gonum/stat"
+)
+
+// Kernel used for kernel density estimation
+type Kernel int
+
+const (
+	GaussianKernel Kernel = iota
+	EpanechnikovKernel
+)
+
+// BandwidthRule selects the bandwidth used
+// for kernel density estimation.
+type BandwidthRule int
+
+const (
+	// Silverman's rule of thumb, which is
+	// robust against outliers
+	Silverman BandwidthRule = iota
+	// Scott's rule of thumb, optimal for
+	// normal data
+	Scott
+	// Least squares cross-validation, which
+	// minimizes an estimate of the integrated
+	// squared error
+	CrossValidation
+)
+
+// Gaussian kernel is truncated at this
+// many bandwidths (exp(-18) ~ 1e-8)
+const gaussianReach = 6
+
+// Grid used for cross-validation
+const crossValidationBins = 4096
+const crossValidationSteps = 64
+
+// Density of the kernel, with unit bandwidth
+func (k Kernel) prob(t float64) float64 {
+	if k == EpanechnikovKernel {
+		if t < -1 || t > 1 {
+			return 0
+		}
+		return 0.75 * (1 - t*t)
+	}
+	return math.Exp(-t*t/2) / math.Sqrt(2*math.Pi)
+}
+
+// Density of the sum of two kernels,
+// i.e. the kernel convolved with itself
+func (k Kernel) conv(t float64) float64 {
+	if k == EpanechnikovKernel {
+		t = math.Abs(t)
+		if t > 2 {
+			return 0
+		}
+		return 3.0 / 160.0 * (2 - t) * (2 - t) * (2 - t) * (t*t + 6*t + 4)
+	}
+	return math.Exp(-t*t/4) / math.Sqrt(4*math.Pi)
+}
+
+// Inverse CDF of the kernel
+func (k Kernel) transform(u float64) float64 {
+	if k == EpanechnikovKernel {
+		// Solves 1/2 + 3/4 t - 1/4 t^3 = u
+		return 2 * math.Sin(math.Asin(2*u-1)/3)
+	}
+	return NormalDist{0, 1}.Transform(u)
+}
+
+// Distance beyond which the kernel vanishes
+func (k Kernel) reach() float64 {
+	if k == EpanechnikovKernel {
+		return 1
+	}
+	return gaussianReach
+}
+
+// Bandwidth of the kernel, which is equivalent to a
+// Gaussian with unit bandwidth (ratio of canonical
+// bandwidths (R(K) / mu_2(K)^2)^(1/5)).
+func (k Kernel) canonical() float64 {
+	if k == EpanechnikovKernel {
+		return 2.2138
+	}
+	return 1
+}
+
+// KDE estimates a probability density from
+// samples, by placing a kernel at every
+// sample:
+//
+//     p(x) = 1/(n h) sum_i K((x - x_i) / h)
+//
+// The bandwidth h is chosen using Rule, unless
+// H is positive. If Bins is positive, the
+// density is evaluated on a grid using FFT
+// convolution, which is faster for many samples.
+type KDE struct {
+	Kernel Kernel
+	Rule   BandwidthRule
+	H      float64
+	Bins   int
+}
+
+type kdeDist struct {
+	samples []float64
+	h       float64
+	kernel  Kernel
+}
+
+// Bandwidth returns the bandwidth used for
+// the given samples.
+func (k KDE) Bandwidth(samples []float64) (float64, error) {
+	if k.H > 0 {
+		return k.H, nil
+	}
+	if len(samples) < 2 {
+		return 0, errors.New("need at least two samples")
+	}
+	sorted := make([]float64, len(samples))
+	copy(sorted, samples)
+	sort.Float64s(sorted)
+
+	n := float64(len(sorted))
+	sigma := stat.StdDev(sorted, nil)
+	iqr := stat.Quantile(0.75, stat.Empirical, sorted, nil) - stat.Quantile(0.25, stat.Empirical, sorted, nil)
+	var h float64
+	switch k.Rule {
+	case Scott:
+		h = 1.06 * sigma * math.Pow(n, -0.2)
+	case Silverman, CrossValidation:
+		spread := sigma
+		if iqr > 0 {
+			spread = math.Min(sigma, iqr/1.34)
+		}
+		h = 0.9 * spread * math.Pow(n, -0.2)
+	default:
+		return 0, errors.New("unknown bandwidth rule")
+	}
+	h *= k.Kernel.canonical()
+	if !(h > 0) || math.IsInf(h, 0) {
+		return 0, errors.New("samples need to have a finite, non-zero spread")
+	}
+	if k.Rule == CrossValidation {
+		h = k.crossValidate(sorted, h)
+	}
+	return h, nil
+}
+
+// Least squares cross-validation, with the pair
+// distances binned on a grid:
+//
+//     LSCV(h) = \int p^2 dx - 2/n sum_i p_{-i}(x_i)
+//
+// The minimum is searched on a logarithmic grid
+// around the rule of thumb bandwidth h0.
+func (k KDE) crossValidate(sorted []float64, h0 float64) float64 {
+	m := crossValidationBins
+	min, max := sorted[0], sorted[len(sorted)-1]
+	delta := (max - min) / float64(m-1)
+	weights := linearBinning(sorted, min, delta, m)
+
+	// Number of sample pairs at every lag
+	lags := make([]float64, m)
+	for i := range weights {
+		for j := i; j < m; j++ {
+			lags[j-i] += weights[i] * weights[j]
+		}
+	}
+	for i := 1; i < m; i++ {
+		lags[i] *= 2
+	}
+
+	// Pairs of every sample with itself, which are
+	// spread to lags 0 and 1 by the binning
+	var self0, self1 float64
+	for _, x := range sorted {
+		t := (x - min) / delta
+		f := t - math.Floor(t)
+		self0 += (1-f)*(1-f) + f*f
+		self1 += 2 * f * (1 - f)
+	}
+
+	n := float64(len(sorted))
+	lscv := func(h float64) float64 {
+		var sq, loo float64
+		for i, c := range lags {
+			sq += c * k.Kernel.conv(float64(i)*delta/h)
+			loo += c * k.Kernel.prob(float64(i)*delta/h)
+		}
+		loo -= self0*k.Kernel.prob(0) + self1*k.Kernel.prob(delta/h)
+		return sq/(n*n*h) - 2*loo/(n*(n-1)*h)
+	}
+
+	best, bestScore := h0, lscv(h0)
+	for i := 0; i <= crossValidationSteps; i++ {
+		// Search h0 / 20 to 4 h0
+		h := h0 * math.Exp(math.Log(0.05)+float64(i)*math.Log(80)/crossValidationSteps)
+		if h < 10*delta {
+			// Binning is too coarse
+			continue
+		}
+		if score := lscv(h); score < bestScore {
+			best, bestScore = h, score
+		}
+	}
+	return best
+}
+
+// Distribute unit weights of samples onto a grid
+// with given offset and spacing, linearly between
+// the neighbouring grid points.
+func linearBinning(samples []float64, offset, delta float64, m int) []float64 {
+	weights := make([]float64, m)
+	for _, x := range samples {
+		t := (x - offset) / delta
+		i := int(math.Floor(t))
+		if i < 0 {
+			i, t = 0, 0
+		} else if i >= m-1 {
+			i, t = m-2, float64(m-1)
+		}
+		f := t - float64(i)
+		weights[i] += 1 - f
+		weights[i+1] += f
+	}
+	return weights
+}
+
+// Fit returns the estimated density as a
+// distribution. Sampling selects a sample
+// uniformly and adds a deviate from the kernel.
+//
+// Prob is evaluated in O(log(n) + k), where
+// k is the number of samples within the reach
+// of the kernel. If Bins is positive, the density
+// is instead tabulated on Bins grid points, and
+// the distribution samples by inverting its
+// tabulated CDF.
+func (k KDE) Fit(samples []float64) (Distribution, error) {
+	h, err := k.Bandwidth(samples)
+	if err != nil {
+		return nil, err
+	}
+	if len(samples) == 0 {
+		return nil, errors.New("need at least one sample")
+	}
+	sorted := make([]float64, len(samples))
+	copy(sorted, samples)
+	sort.Float64s(sorted)
+	for _, x := range []float64{sorted[0], sorted[len(sorted)-1]} {
+		if math.IsInf(x, 0) || math.IsNaN(x) {
+			return nil, errors.New("samples must be finite")
+		}
+	}
+	if k.Bins > 0 {
+		return k.binned(sorted, h)
+	}
+	return kdeDist{sorted, h, k.Kernel}, nil
+}
+
+// Evaluate the density on a grid, which extends the
+// range of the samples by the reach of the kernel on
+// both sides, so the circular convolution does not
+// wrap around.
+func (k KDE) binned(sorted []float64, h float64) (Distribution, error) {
+	m := k.Bins
+	if m < 4 {
+		return nil, errors.New("need at least 4 bins")
+	}
+	reach := k.Kernel.reach() * h
+	lo, hi := sorted[0]-reach, sorted[len(sorted)-1]+reach
+	delta := (hi - lo) / float64(m-1)
+	if delta > h/2 {
+		return nil, errors.New("bins are too coarse for the bandwidth")
+	}
+	weights := linearBinning(sorted, lo, delta, m)
+
+	// Kernel centered on kernel[m/2]
+	kernel := make([]float64, m)
+	for i := range kernel {
+		t := float64(i-m/2) * delta / h
+		if math.Abs(t) <= k.Kernel.reach() {
+			kernel[i] = k.Kernel.prob(t)
+		}
+	}
+	density := signal.FFTConvolve(weights, kernel)
+
+	xs := make([]float64, m)
+	cdf := make([]float64, m)
+	for i := range xs {
+		xs[i] = lo + delta*float64(i)
+		// Rounding errors of the FFT
+		density[i] = math.Max(density[i], 0)
+		if i > 0 {
+			cdf[i] = cdf[i-1] + delta*(density[i-1]+density[i])/2
+		}
+	}
+	prob := func(x float64) float64 {
+		t := (x - lo) / delta
+		i := int(math.Min(math.Max(math.Floor(t), 0), float64(m-2)))
+		f := t - float64(i)
+		return (1-f)*density[i] + f*density[i+1]
+	}
+	return newTabulatedDist(prob, xs, cdf)
+}
+
+func (d kdeDist) Transform(x float64) float64 {
+	n := float64(len(d.samples))
+	i := int(x * n)
+	if i >= len(d.samples) {
+		i = len(d.samples) - 1
+	}
+	// Remaining randomness selects the kernel deviate
+	u := math.Min(math.Max(x*n-float64(i), 0), math.Nextafter(1, 0))
+	return d.samples[i] + d.h*d.kernel.transform(u)
+}
+
+func (d kdeDist) Prob(x float64) float64 {
+	reach := d.kernel.reach() * d.h
+	from := sort.SearchFloat64s(d.samples, x-reach)
+	var p float64
+	for i := from; i < len(d.samples) && d.samples[i] <= x+reach; i++ {
+		p += d.kernel.prob((x - d.samples[i]) / d.h)
+	}
+	return p / (float64(len(d.samples)) * d.h)
+}
+
+func (d kdeDist) Support() (float64, float64) {
+	if d.kernel == EpanechnikovKernel {
+		return d.samples[0] - d.h, d.samples[len(d.samples)-1] + d.h
+	}
+	return math.Inf(-1), math.Inf(+1)
+}
@@ -0,0 +1,71 @@
+package casino
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestKDE(t *testing.T) {
+	target, err := NewMixture([]float64{0.4, 0.6}, NormalDist{-2, 0.5}, NormalDist{1, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewSampler(target, Seed())
+	samples := make([]float64, 20000)
+	for i := range samples {
+		samples[i] = s.Sample()
+	}
+
+	for _, kernel := range []Kernel{GaussianKernel, EpanechnikovKernel} {
+		for _, rule := range []BandwidthRule{Silverman, Scott, CrossValidation} {
+			for _, bins := range []int{0, 1024} {
+				kde := KDE{Kernel: kernel, Rule: rule, Bins: bins}
+				h, err := kde.Bandwidth(samples)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if h < 0.05 || h > 0.8 {
+					t.Error(fmt.Sprintf("%v: bandwidth %v is not sensible", kde, h))
+				}
+				dist, err := kde.Fit(samples)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				// The estimate is close to the target
+				var ise float64
+				for x := -5.0; x < 5; x += 0.01 {
+					d := dist.Prob(x) - target.Prob(x)
+					ise += d * d * 0.01
+				}
+				if ise > 2e-3 {
+					t.Error(fmt.Sprintf("%v: integrated squared error %v", kde, ise))
+				}
+				// Samples follow the estimate
+				helperTestHistogram(dist, -4.95, 5.05, t)
+			}
+		}
+	}
+
+	// Cross-validation improves on the rules of thumb
+	// for the bimodal target
+	silverman, _ := KDE{Rule: Scott}.Bandwidth(samples)
+	cv, _ := KDE{Rule: CrossValidation}.Bandwidth(samples)
+	if cv >= silverman {
+		t.Error(fmt.Sprintf("cross-validated bandwidth %v should be smaller than %v", cv, silverman))
+	}
+
+	// Fixed bandwidth
+	if h, _ := (KDE{H: 0.3}).Bandwidth(samples); h != 0.3 {
+		t.Error(fmt.Sprintf("bandwidth %v should be 0.3", h))
+	}
+	if _, err := (KDE{}).Fit([]float64{1}); err == nil {
+		t.Error("should reject single sample")
+	}
+	if _, err := (KDE{}).Fit([]float64{1, 1, 1}); err == nil {
+		t.Error("should reject samples without spread")
+	}
+	if _, err := (KDE{Bins: 16}).Fit(samples); err == nil {
+		t.Error("should reject coarse bins")
+	}
+}
@@ -0,0 +1,200 @@
+package casino
+
+import (
+	"math"
+
+	"golang.org/x/exp/rand"
+)
+
+// Defaults for Metropolis chains
+const defaultMetropolisWidth = 1
+const defaultMetropolisAcceptance = 0.44
+const metropolisTuneBatch = 50
+
+// Proposal generates proposals for
+// the Metropolis-Hastings algorithm.
+type Proposal interface {
+	// Propose returns a new state, given
+	// the current state and a uniform deviate.
+	Propose(x, u float64) float64
+	// Prob returns the proposal density
+	// q(to | from).
+	Prob(to, from float64) float64
+}
+
+// RandomWalk proposes x + s, where s is
+// drawn from Step. If Step is symmetric
+// about zero, so is the proposal.
+type RandomWalk struct {
+	Step Distribution
+}
+
+func (r RandomWalk) Propose(x, u float64) float64 {
+	return x + r.Step.Transform(u)
+}
+
+func (r RandomWalk) Prob(to, from float64) float64 {
+	return r.Step.Prob(to - from)
+}
+
+// Independent proposes states drawn from
+// the contained distribution, regardless
+// of the current state.
+type Independent struct {
+	Distribution
+}
+
+func (i Independent) Propose(_, u float64) float64 {
+	return i.Transform(u)
+}
+
+func (i Independent) Prob(to, _ float64) float64 {
+	return i.Distribution.Prob(to)
+}
+
+// Metropolis implements the Metropolis-Hastings
+// algorithm, which produces a Markov chain with
+// stationary distribution proportional to Pi.
+//
+// The first Burn states of the chain are discarded,
+// after that every Thin-th state is returned by
+// Sample. If no Proposal is given, a Gaussian random
+// walk is used, whose width is tuned during burn in
+// towards the Acceptance rate. A custom Proposal is
+// used as given and not tuned, so Width and Acceptance
+// only apply to the default random walk.
+//
+// Defaults are resolved when the chain starts and
+// are not written back to the exported fields.
+//
+// The type can be used in it's null value, once Pi
+// is set. A chain is not safe for concurrent use;
+// use separate chains (e.g. as the Generators of
+// an Expectation) to run several workers.
+type Metropolis struct {
+	// Un-normalized target density
+	Pi func(float64) float64
+	// Proposal distribution, if this is nil
+	// a Gaussian random walk is used
+	Proposal Proposal
+	// Initial width of the Gaussian random
+	// walk (defaults to 1)
+	Width float64
+	// Initial state of the chain
+	Start float64
+	// Samples to discard and chain steps
+	// per returned sample (defaults to 1)
+	Burn, Thin int
+	// Acceptance rate targeted during burn
+	// in (defaults to 0.44, which is optimal
+	// for one-dimensional targets)
+	Acceptance float64
+	// Seed for the chain's random numbers
+	Seed uint64
+
+	rng        *rand.Rand
+	x, pi      float64
+	burned     bool
+	stats      Stats
+	tune       bool
+	walkWidth  float64
+	thin       int
+	acceptance float64
+}
+
+// init helper, see Expectation.init
+func (m *Metropolis) init() {
+	if m.rng != nil {
+		return
+	}
+	m.rng = rand.New(rand.NewSource(m.Seed))
+	m.x, m.pi = m.Start, m.Pi(m.Start)
+	m.thin, m.acceptance = m.Thin, m.Acceptance
+	if m.thin < 1 {
+		m.thin = 1
+	}
+	if m.acceptance <= 0 || m.acceptance >= 1 {
+		m.acceptance = defaultMetropolisAcceptance
+	}
+	if m.Proposal == nil {
+		m.tune = true
+		m.walkWidth = m.Width
+		if m.walkWidth <= 0 {
+			m.walkWidth = defaultMetropolisWidth
+		}
+	}
+}
+
+// Take a single step of the chain, returns
+// true if the proposal was accepted.
+func (m *Metropolis) step() bool {
+	var y, piY, ratio float64
+	if m.Proposal == nil {
+		// Symmetric, so the proposal densities cancel
+		y = NormalDist{m.x, m.walkWidth}.Transform(m.rng.Float64())
+		piY = m.Pi(y)
+		ratio = piY / m.pi
+	} else {
+		y = m.Proposal.Propose(m.x, m.rng.Float64())
+		piY = m.Pi(y)
+		ratio = piY * m.Proposal.Prob(m.x, y) / (m.pi * m.Proposal.Prob(y, m.x))
+	}
+	// NaN ratios (e.g. 0/0) are always accepted, so the
+	// chain can leave regions where Pi vanishes
+	if !(ratio < 1) || m.rng.Float64() < ratio {
+		m.x, m.pi = y, piY
+		return true
+	}
+	return false
+}
+
+// Run the burn in, tuning the random walk
+// width in batches if possible. The adaptation
+// diminishes with every batch, so the width
+// settles down.
+func (m *Metropolis) burn() {
+	accepted := 0
+	for i := 1; i <= m.Burn; i++ {
+		if m.step() {
+			accepted++
+		}
+		if m.tune && i%metropolisTuneBatch == 0 {
+			rate := float64(accepted) / metropolisTuneBatch
+			gain := 2 / math.Sqrt(float64(i/metropolisTuneBatch))
+			m.walkWidth *= math.Exp(gain * (rate - m.acceptance))
+			accepted = 0
+		}
+	}
+	m.stats.Burn = m.Burn
+	m.burned = true
+}
+
+// Sample implements Generator.
+func (m *Metropolis) Sample() float64 {
+	m.init()
+	if !m.burned {
+		m.burn()
+	}
+	for i := 0; i < m.thin; i++ {
+		m.stats.Trials++
+		if m.step() {
+			m.stats.Accepted++
+		}
+	}
+	return m.x
+}
+
+// WalkWidth returns the current width of the
+// random walk proposal. This is only
+// meaningful if no Proposal was given.
+func (m *Metropolis) WalkWidth() float64 {
+	m.init()
+	return m.walkWidth
+}
+
+// Stats returns the number of burned
+// samples, as well as the number of proposals
+// made and accepted after the burn in.
+func (m *Metropolis) Stats() Stats {
+	return m.stats
+}
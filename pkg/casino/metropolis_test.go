@@ -0,0 +1,80 @@
+package casino
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestMetropolis(t *testing.T) {
+	const chains = 16
+	seeds := Noise(chains)
+
+	// Gaussian random walk, starting far from optimal
+	gens := make([]Generator, chains)
+	for i := range gens {
+		gens[i] = &Metropolis{
+			Pi:    func(x float64) float64 { return math.Exp(-x * x / 2) },
+			Width: 20,
+			Start: 5,
+			Burn:  2000,
+			Thin:  2,
+			Seed:  seeds[i],
+		}
+	}
+	e := Expectation{Function: func(x float64) float64 { return x * x }, Generators: gens}
+	res := e.Refine(20000, chains)
+	if math.Abs(res.Value-1) > 0.05 {
+		t.Error(fmt.Sprintf("(value) %v is not approximately 1", res.Value))
+	}
+	if res.Burn != 2000*chains {
+		t.Error(fmt.Sprintf("burned %v samples, should be %v", res.Burn, 2000*chains))
+	}
+	for _, gen := range gens {
+		m := gen.(*Metropolis)
+		stats := m.Stats()
+		if stats.Trials != 2*20000 {
+			t.Error(fmt.Sprintf("chain made %v proposals, should be %v", stats.Trials, 2*20000))
+		}
+		if math.Abs(stats.AcceptanceRate()-defaultMetropolisAcceptance) > 0.1 {
+			t.Error(fmt.Sprintf("acceptance rate %v not tuned (width %v)", stats.AcceptanceRate(), m.WalkWidth()))
+		}
+	}
+
+	// Custom, non-symmetric proposal
+	m := Metropolis{
+		Pi:       func(x float64) float64 { return ExponentialDist{1}.Prob(x) },
+		Proposal: Independent{ExponentialDist{0.5}},
+		Start:    1,
+		Burn:     1000,
+		Seed:     Seed(),
+	}
+	var mean float64
+	for i := 0; i < 100000; i++ {
+		mean += m.Sample() / 100000
+	}
+	if math.Abs(mean-1) > 0.05 {
+		t.Error(fmt.Sprintf("(value) %v is not approximately 1", mean))
+	}
+	if m.Thin != 0 || m.Acceptance != 0 {
+		t.Error(fmt.Sprintf("configuration was changed: %v, %v", m.Thin, m.Acceptance))
+	}
+
+	// Pi is evaluated once per proposal
+	calls := 0
+	for _, proposal := range []Proposal{nil, RandomWalk{NormalDist{0, 1}}} {
+		calls = 0
+		m := Metropolis{
+			Pi:       func(x float64) float64 { calls++; return math.Exp(-x * x / 2) },
+			Proposal: proposal,
+			Burn:     100,
+			Seed:     Seed(),
+		}
+		for i := 0; i < 1000; i++ {
+			m.Sample()
+		}
+		if calls != 1+100+1000 {
+			t.Error(fmt.Sprintf("Pi was evaluated %v times, should be %v", calls, 1+100+1000))
+		}
+	}
+}
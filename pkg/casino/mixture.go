@@ -0,0 +1,135 @@
+package casino
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+type mixtureDist struct {
+	weights, cumulative []float64
+	components          []Distribution
+}
+
+// NewMixture creates a mixture of the given components,
+// where every component is chosen with probability
+// proportional to its weight. The weights do not need
+// to be normalized, but must be non-negative and finite.
+//
+// Transform selects a component using the uniform
+// deviate, and rescales the remaining part of the
+// deviate to pass it on to the component's Transform.
+// The support of the mixture is the smallest interval
+// containing the supports of all components with
+// non-zero weight.
+func NewMixture(weights []float64, components ...Distribution) (Distribution, error) {
+	if len(components) == 0 {
+		return nil, errors.New("need to provide at least one component")
+	}
+	if len(weights) != len(components) {
+		return nil, errors.New("need to provide the same amount of weights and components")
+	}
+
+	var total float64
+	for i, w := range weights {
+		if w < 0 || math.IsInf(w, 0) || math.IsNaN(w) {
+			return nil, errors.New("weights must be non-negative and finite")
+		}
+		if components[i] == nil {
+			return nil, errors.New("components can not be nil")
+		}
+		total += w
+	}
+	if total <= 0 {
+		return nil, errors.New("need at least one positive weight")
+	}
+
+	dist := mixtureDist{
+		weights:    make([]float64, len(weights)),
+		cumulative: make([]float64, len(weights)),
+		components: make([]Distribution, len(components)),
+	}
+	copy(dist.components, components)
+	var sum float64
+	for i, w := range weights {
+		dist.weights[i] = w / total
+		sum += w
+		dist.cumulative[i] = sum / total
+	}
+	return dist, nil
+}
+
+func (d mixtureDist) Transform(x float64) float64 {
+	// First component with cumulative weight above x,
+	// which skips components with zero weight
+	k := sort.Search(len(d.cumulative), func(i int) bool {
+		return d.cumulative[i] > x
+	})
+	if k == len(d.cumulative) {
+		// Rounding can leave the last cumulative
+		// weight below 1
+		for k--; d.weights[k] == 0; k-- {
+		}
+	}
+	var lo float64
+	if k > 0 {
+		lo = d.cumulative[k-1]
+	}
+	// Rescale the deviate to [0, 1)
+	u := (x - lo) / d.weights[k]
+	u = math.Min(math.Max(u, 0), math.Nextafter(1, 0))
+	return d.components[k].Transform(u)
+}
+
+func (d mixtureDist) Prob(x float64) float64 {
+	var p float64
+	for i, c := range d.components {
+		if d.weights[i] == 0 {
+			continue
+		}
+		// Components are not required to
+		// vanish outside their support
+		if min, max := c.Support(); x < min || x > max {
+			continue
+		}
+		p += d.weights[i] * c.Prob(x)
+	}
+	return p
+}
+
+func (d mixtureDist) Support() (float64, float64) {
+	min, max := math.Inf(+1), math.Inf(-1)
+	for i, c := range d.components {
+		if d.weights[i] == 0 {
+			continue
+		}
+		a, b := c.Support()
+		min, max = math.Min(min, a), math.Max(max, b)
+	}
+	return min, max
+}
+
+// MixtureFamily returns an APIS Family, where
+// every proposal is a mixture of the given
+// families with the same location and scale.
+// For example, mixing a Gaussian with a small
+// weight of heavy tailed StudentTFamily proposals
+// yields defensive proposals.
+func MixtureFamily(weights []float64, families ...func(mu, sigma float64) Distribution) (func(mu, sigma float64) Distribution, error) {
+	components := make([]Distribution, len(families))
+	for i, family := range families {
+		components[i] = family(0, 1)
+	}
+	if _, err := NewMixture(weights, components...); err != nil {
+		return nil, err
+	}
+	return func(mu, sigma float64) Distribution {
+		components := make([]Distribution, len(families))
+		for i, family := range families {
+			components[i] = family(mu, sigma)
+		}
+		// The weights were checked above
+		dist, _ := NewMixture(weights, components...)
+		return dist
+	}, nil
+}
@@ -0,0 +1,90 @@
+package casino
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestMixture(t *testing.T) {
+	linear, err := NewLinearDist(0, 2, 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mixtures := [][]Distribution{
+		{NormalDist{-2, 0.5}, NormalDist{2, 1}},
+		{ExponentialDist{1}, UniDistAB{-3, -1}, CauchyDist{0, 1}},
+		// Zero weight and overlapping finite support
+		{linear, BetaDist{2, 2}, GammaDist{2, 1}},
+	}
+	weights := [][]float64{
+		{0.3, 0.7},
+		{2, 1, 1},
+		{1, 3, 0},
+	}
+	supports := []float64{
+		math.Inf(-1), math.Inf(1),
+		math.Inf(-1), math.Inf(1),
+		0, 2,
+	}
+	for i := range mixtures {
+		dist, err := NewMixture(weights[i], mixtures[i]...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if min, max := dist.Support(); min != supports[2*i] || max != supports[2*i+1] {
+			t.Error(fmt.Sprintf("support [%v, %v] should be [%v, %v]", min, max, supports[2*i], supports[2*i+1]))
+		}
+		// Support edges should not lie on bin edges
+		helperTestHistogram(dist, -4.9, 5.1, t)
+	}
+
+	for _, w := range [][]float64{{1}, {0, 0}, {1, -1}, {1, math.Inf(1)}} {
+		if _, err := NewMixture(w, NormalDist{0, 1}, NormalDist{1, 1}); err == nil {
+			t.Error(fmt.Sprintf("weights %v should be rejected", w))
+		}
+	}
+	if _, err := NewMixture(nil); err == nil {
+		t.Error("should reject empty mixture")
+	}
+}
+
+// A bimodal target is estimated with
+// defensive mixture proposals.
+func TestMixtureAPIS(t *testing.T) {
+	target, err := NewMixture([]float64{0.5, 0.5}, NormalDist{-3, 0.5}, NormalDist{3, 0.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	family, err := MixtureFamily([]float64{0.9, 0.1}, func(mu, sigma float64) Distribution {
+		return NormalDist{mu, sigma}
+	}, StudentTFamily(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mus, sigmas := APISFamily(NewSampler(UniDistAB{-5, 5}, Seed()), 16)
+	apis := APIS{
+		Function:   func(x float64) float64 { return x * x },
+		Pi:         func(x float64) float64 { return 2 * target.Prob(x) },
+		Epochs:     32,
+		Iterations: 32,
+		Mus:        mus,
+		Sigmas:     sigmas,
+		Family:     family,
+		Seeds:      Noise(16),
+	}
+	I, Z, err := apis.Estimate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(I.Value-9.25) > 5*I.StdErr+1e-2 {
+		t.Error(fmt.Sprintf("I = %v +- %v should be 9.25", I.Value, I.StdErr))
+	}
+	if math.Abs(Z.Value-2) > 5*Z.StdErr+1e-2 {
+		t.Error(fmt.Sprintf("Z = %v +- %v should be 2", Z.Value, Z.StdErr))
+	}
+
+	if _, err := MixtureFamily([]float64{1}, StudentTFamily(1), StudentTFamily(2)); err == nil {
+		t.Error("should reject mismatched weights")
+	}
+}
@@ -1,6 +1,9 @@
 package casino
 
-import "fmt"
+import (
+	"fmt"
+	"sync"
+)
 
 var noise []uint64 = []uint64{
 	374526676, 349920455, 470003742, 197127715, 952050880, 723681147, 841806478, 521308297, 643590642,
@@ -121,6 +124,7 @@ var noise []uint64 = []uint64{
 
 // last used index in noise array
 var last int = 0
+var lastLock sync.Mutex
 
 // Return good random numbers, generated
 // from atmospheric noise via random.org.
@@ -130,10 +134,17 @@ var last int = 0
 // Note that a fresh copy of the data is
 // returned every time, so the user is free
 // to modify the resulting slice.
+//
+// Noise is safe for concurrent use, but
+// cycles through about a thousand fixed
+// numbers. Use a SeedSource to obtain an
+// unlimited number of independent seeds.
 func Noise(n int) []uint64 {
 	if n > len(noise) {
 		panic(fmt.Sprintf("n can be at most %v", len(noise)))
 	}
+	lastLock.Lock()
+	defer lastLock.Unlock()
 	res := make([]uint64, n, n)
 	for i := range res {
 		res[i] = noise[last]
@@ -145,6 +156,8 @@ func Noise(n int) []uint64 {
 // Seed returns a single seed taken
 // from noise.
 func Seed() (seed uint64) {
+	lastLock.Lock()
+	defer lastLock.Unlock()
 	seed = noise[last]
 	last = (last + 1) % len(noise)
 	return
@@ -27,6 +27,23 @@ type Supporter interface {
 	Support() (float64, float64)
 }
 
+// CDFer calculates the cumulative
+// distribution function. Distributions
+// implementing CDFer use the inverse of
+// their CDF as Transform, which allows
+// to wrap them, see NewTruncated.
+type CDFer interface {
+	CDF(float64) float64
+}
+
+// Density is a probability density
+// with known support, which may not
+// be possible to sample from directly.
+type Density interface {
+	Prober
+	Supporter
+}
+
 // Distribution is sufficient to
 // sample from a probability distribution
 type Distribution interface {
@@ -35,9 +52,17 @@ type Distribution interface {
 	Supporter
 }
 
+// Generator produces a stream of
+// samples.
+type Generator interface {
+	Sample() float64
+}
+
+// Sampler is a Generator, which samples
+// from a known distribution.
 type Sampler interface {
 	Distribution
-	Sample() float64
+	Generator
 }
 
 // Sampler allows to sample from a random distribution
@@ -90,6 +115,10 @@ func (_ UniDist) Support() (float64, float64) {
 	return 0, 1
 }
 
+func (_ UniDist) CDF(x float64) float64 {
+	return math.Max(0, math.Min(1, x))
+}
+
 // Implements a uniform distribution [A, B)
 type UniDistAB struct {
 	A, B float64
@@ -110,6 +139,10 @@ func (d UniDistAB) Support() (float64, float64) {
 	return d.A, d.B
 }
 
+func (d UniDistAB) CDF(x float64) float64 {
+	return math.Max(0, math.Min(1, (x-d.A)/(d.B-d.A)))
+}
+
 type linearDist struct {
 	a, b, alpha, beta, gamma float64
 }
@@ -141,12 +174,15 @@ func (d linearDist) Transform(x float64) float64 {
 	p := d.beta / d.alpha
 	x /= d.gamma
 	sqrt := math.Sqrt(p*p + d.a*d.a + 2*d.a*p + 2*x/d.alpha)
-	// +/- solution depends on sign of a
+	// +/- solution depends on sign of a, and
+	// rounding can leave the support at the edges
+	var y float64
 	if d.alpha < 0 {
-		return -(p + sqrt)
+		y = -(p + sqrt)
 	} else {
-		return -p + sqrt
+		y = -p + sqrt
 	}
+	return math.Min(math.Max(y, d.a), d.b)
 }
 
 func (d linearDist) Prob(x float64) float64 {
@@ -160,6 +196,11 @@ func (d linearDist) Support() (float64, float64) {
 	return d.a, d.b
 }
 
+func (d linearDist) CDF(x float64) float64 {
+	x = math.Max(d.a, math.Min(d.b, x))
+	return math.Max(0, math.Min(1, d.gamma*(d.alpha/2*(x*x-d.a*d.a)+d.beta*(x-d.a))))
+}
+
 // Approximately implements a normal distribution
 // with given mean and variance. Sigma must be positive.
 type NormalDist struct {
@@ -167,6 +208,12 @@ type NormalDist struct {
 }
 
 func (d NormalDist) Transform(x float64) float64 {
+	// Samplers produce deviates in [0, 1), which
+	// map to -Inf at 0. Use half of the smallest
+	// non-zero deviate instead.
+	if x <= 0 {
+		x = 0x1p-54
+	}
 	// \sqrt{2} \sigma \erf^{-1}(2x - 1) + \mu = y
 	return math.Erfinv(2*x-1)*math.Sqrt2*d.Sigma + d.Mu
 }
@@ -178,3 +225,7 @@ func (d NormalDist) Prob(x float64) float64 {
 func (d NormalDist) Support() (float64, float64) {
 	return math.Inf(-1), math.Inf(+1)
 }
+
+func (d NormalDist) CDF(x float64) float64 {
+	return stdNormalCDF((x - d.Mu) / d.Sigma)
+}
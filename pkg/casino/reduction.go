@@ -0,0 +1,106 @@
+package casino
+
+import (
+	"gonum.org/v1/gonum/mat"
+)
+
+// Set up the vector expectation, which
+// tracks the Function and Controls jointly.
+// With antithetic pairs, the first sample
+// of every pair is tracked as well, to
+// estimate the variance without reduction.
+func (exp *Expectation) initReduction() {
+	k := len(exp.Controls)
+	if len(exp.ControlMeans) != k {
+		panic("need to provide the same amount of controls and control means")
+	}
+	exp.vec = &ExpectationVec{
+		Dim:    1 + k,
+		Seeds:  exp.Seeds,
+		Source: exp.Source,
+	}
+	if exp.Antithetic {
+		if exp.Distribution == nil {
+			panic("antithetic sampling requires a distribution")
+		}
+		// Sample uniform deviates and transform
+		// them ourselves
+		exp.vec.Dim++
+		exp.vec.Distribution = UniDist{}
+		exp.vec.Function = func(u float64, dst []float64) {
+			x, y := exp.Transform(u), exp.Transform(1-u)
+			f := exp.Function(x)
+			dst[0] = 0.5 * (f + exp.Function(y))
+			for j, g := range exp.Controls {
+				dst[1+j] = 0.5 * (g(x) + g(y))
+			}
+			dst[1+k] = f
+		}
+	} else {
+		exp.vec.Distribution = exp.Distribution
+		exp.vec.Generators = exp.Generators
+		exp.vec.Function = func(x float64, dst []float64) {
+			dst[0] = exp.Function(x)
+			for j, g := range exp.Controls {
+				dst[1+j] = g(x)
+			}
+		}
+	}
+}
+
+// Combine the vector result into the
+// controlled estimate
+//
+//     f - beta (g - mu)
+//
+// where beta = Cov(g)^-1 Cov(g, f) minimizes
+// the variance of the estimate.
+func (exp *Expectation) reduce(rv ResultVec) Result {
+	k := len(exp.Controls)
+	h := make([]float64, rv.Covariance.Symmetric())
+	h[0] = 1
+	beta := make([]float64, k)
+	if k > 0 && rv.Trials > k+1 {
+		var cov mat.SymDense
+		cov.SubsetSym(rv.Covariance, seq(1, 1+k))
+		var chol mat.Cholesky
+		if chol.Factorize(&cov) {
+			cross := mat.NewVecDense(k, nil)
+			for j := 0; j < k; j++ {
+				cross.SetVec(j, rv.Covariance.At(0, 1+j))
+			}
+			var b mat.VecDense
+			if err := chol.SolveVecTo(&b, cross); err == nil {
+				for j := range beta {
+					beta[j] = b.AtVec(j)
+				}
+			}
+		}
+	}
+	for j := 0; j < k; j++ {
+		h[1+j] = -beta[j]
+	}
+
+	res := rv.Linear(h)
+	for j := 0; j < k; j++ {
+		res.Value += beta[j] * exp.ControlMeans[j]
+	}
+
+	// Variance per function evaluation without
+	// reduction, relative to the achieved one
+	naive, evals := rv.Covariance.At(0, 0), 1.0
+	if exp.Antithetic {
+		naive, evals = rv.Covariance.At(1+k, 1+k), 2
+	}
+	res.Reduction = naive / (evals * res.Variance)
+	return res
+}
+
+// Returns the indices from, ..., to-1
+func seq(from, to int) []int {
+	res := make([]int, to-from)
+	for i := range res {
+		res[i] = from + i
+	}
+	return res
+}
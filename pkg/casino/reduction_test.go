@@ -0,0 +1,71 @@
+package casino
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestReduction(t *testing.T) {
+	controls := []func(float64) float64{
+		func(x float64) float64 { return x },
+		func(x float64) float64 { return x * x },
+	}
+	cases := []struct {
+		antithetic bool
+		controls   []func(float64) float64
+		means      []float64
+	}{
+		{true, nil, nil},
+		{false, controls[:1], []float64{0.5}},
+		{false, controls, []float64{0.5, 1.0 / 3.0}},
+		{true, controls[1:], []float64{1.0 / 3.0}},
+	}
+	// Minimal reduction factors
+	reductions := []float64{20, 50, 1000, 1000}
+
+	for i := range cases {
+		e := Expectation{
+			Distribution: UniDist{},
+			Function:     math.Exp,
+			Source:       NewSeedSource(Seed()),
+			Antithetic:   cases[i].antithetic,
+			Controls:     cases[i].controls,
+			ControlMeans: cases[i].means,
+		}
+		res := e.Refine(10000, 8)
+		if res.Trials != 80000 {
+			t.Error(fmt.Sprintf("wrong number of trials %v", res.Trials))
+		}
+		if math.Abs(res.Value-(math.E-1)) > 5*res.StdErr || res.StdErr > 1e-3 {
+			t.Error(fmt.Sprintf("%v: (value) %v (+/- %v) is not approximately %v", i, res.Value, res.StdErr, math.E-1))
+		}
+		if res.Reduction < reductions[i] {
+			t.Error(fmt.Sprintf("%v: variance reduced by %v, expected at least %v", i, res.Reduction, reductions[i]))
+		}
+		if r := e.Result(); r.Value != res.Value || r.Reduction != res.Reduction {
+			t.Error(fmt.Sprintf("%v: result %v does not match %v", i, r, res))
+		}
+	}
+
+	// Controls work with generators
+	gens := make([]Generator, 4)
+	for i := range gens {
+		gens[i] = &ar1{phi: 0.5, s: NewSampler(NormalDist{0, 1}, Seed())}
+	}
+	e := Expectation{
+		Function:     func(x float64) float64 { return x + x*x },
+		Controls:     controls[:1],
+		ControlMeans: []float64{0},
+		Generators:   gens,
+	}
+	res := e.Refine(50000, 4)
+	if math.Abs(res.Value-1) > 5*res.BatchStdErr || res.Reduction < 1.2 {
+		t.Error(fmt.Sprintf("(value) %v (+/- %v) should be 1, reduced by %v", res.Value, res.BatchStdErr, res.Reduction))
+	}
+
+	e = Expectation{Distribution: UniDist{}, Function: math.Exp, Seeds: Noise(1)}
+	if res := e.Refine(10, 1); res.Reduction != 0 {
+		t.Error(fmt.Sprintf("reported reduction %v without variance reduction", res.Reduction))
+	}
+}
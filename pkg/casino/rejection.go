@@ -0,0 +1,160 @@
+package casino
+
+import (
+	"errors"
+	"math"
+	"sync/atomic"
+
+	"golang.org/x/exp/rand"
+)
+
+// Number of proposals used to estimate
+// the envelope constant.
+const rejectionEnvelopeSamples = 1 << 14
+
+// Number of consecutive rejections after
+// which sampling is abandoned.
+const rejectionMaxTries = 1 << 24
+
+// Rejection implements rejection sampling from
+// a target density, using a proposal distribution
+// which can be sampled from directly. Proposals x
+// are accepted with probability
+//
+//     target(x) / (C * proposal(x)).
+//
+// The target does not need to be normalized, but
+// Prob only reports a normalized density if the
+// target is.
+//
+// Rejection implements Sampler. Sample draws from
+// an internal PCG source and is not safe for
+// concurrent use. Transform is safe for concurrent
+// use: it seeds an independent SplitMix64 stream
+// from the passed uniform deviate, which makes
+// Rejection a valid Distribution to be used with
+// NewSampler, Expectation or quad.
+type Rejection struct {
+	target   Density
+	proposal Distribution
+	// Envelope constant, stored as float64 bits
+	c   uint64
+	rng *rand.Rand
+
+	trials, accepted int64
+}
+
+// NewRejection creates a rejection sampler. If c is
+// zero, the envelope constant is estimated from the
+// largest observed ratio target / proposal. Whenever
+// a proposal exceeds the envelope during sampling,
+// the constant is raised. Note that samples drawn
+// before raising the envelope are slightly biased.
+func NewRejection(target Density, proposal Distribution, c float64, seed uint64) (*Rejection, error) {
+	if target == nil || proposal == nil {
+		return nil, errors.New("target and proposal distributions are required")
+	}
+	if c < 0 {
+		return nil, errors.New("envelope constant must be positive")
+	}
+
+	r := &Rejection{
+		target:   target,
+		proposal: proposal,
+		rng:      rand.New(rand.NewSource(seed)),
+	}
+
+	if c == 0 {
+		// Estimate envelope from proposal samples
+		for i := 0; i < rejectionEnvelopeSamples; i++ {
+			x := proposal.Transform(r.rng.Float64())
+			if p := proposal.Prob(x); p > 0 {
+				c = math.Max(c, target.Prob(x)/p)
+			}
+		}
+		if c == 0 || math.IsInf(c, 0) || math.IsNaN(c) {
+			return nil, errors.New("could not estimate envelope constant")
+		}
+		// Leave some room for unobserved peaks
+		c *= 1.1
+	}
+	r.c = math.Float64bits(c)
+
+	return r, nil
+}
+
+// C returns the current envelope constant.
+func (r *Rejection) C() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&r.c))
+}
+
+// Raise the envelope constant to at least c.
+func (r *Rejection) raise(c float64) {
+	for {
+		old := atomic.LoadUint64(&r.c)
+		if math.Float64frombits(old) >= c {
+			return
+		}
+		if atomic.CompareAndSwapUint64(&r.c, old, math.Float64bits(c)) {
+			return
+		}
+	}
+}
+
+// Draw proposals until one is accepted. The uniform
+// deviates are provided by next. Returns NaN if
+// no proposal is accepted.
+func (r *Rejection) sample(next func() float64) float64 {
+	for i := 0; i < rejectionMaxTries; i++ {
+		x := r.proposal.Transform(next())
+		atomic.AddInt64(&r.trials, 1)
+		p := r.proposal.Prob(x)
+		if p <= 0 {
+			continue
+		}
+		ratio := r.target.Prob(x) / p
+		c := r.C()
+		if ratio > c {
+			r.raise(1.1 * ratio)
+			c = r.C()
+		}
+		if next()*c < ratio {
+			atomic.AddInt64(&r.accepted, 1)
+			return x
+		}
+	}
+	return math.NaN()
+}
+
+// Sample implements Sampler.
+func (r *Rejection) Sample() float64 {
+	return r.sample(r.rng.Float64)
+}
+
+// Transform implements Transformer, by running
+// rejection sampling on a stream seeded from x.
+func (r *Rejection) Transform(x float64) float64 {
+	state := math.Float64bits(x)
+	return r.sample(func() float64 {
+		return float64(splitMix64(&state)>>11) / (1 << 53)
+	})
+}
+
+// Prob implements Prober.
+func (r *Rejection) Prob(x float64) float64 {
+	return r.target.Prob(x)
+}
+
+// Support implements Supporter.
+func (r *Rejection) Support() (float64, float64) {
+	return r.target.Support()
+}
+
+// Stats returns the number of proposals made
+// and accepted so far.
+func (r *Rejection) Stats() Stats {
+	return Stats{
+		Trials:   int(atomic.LoadInt64(&r.trials)),
+		Accepted: int(atomic.LoadInt64(&r.accepted)),
+	}
+}
@@ -0,0 +1,69 @@
+package casino
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+// Target from assignment question 5
+type cosSquaredDist struct{}
+
+func (_ cosSquaredDist) Prob(x float64) float64 {
+	if x < -math.Pi || x > math.Pi {
+		return 0
+	}
+	return math.Cos(0.5*x) * math.Cos(0.5*x) / math.Pi
+}
+
+func (_ cosSquaredDist) Support() (float64, float64) {
+	return -math.Pi, math.Pi
+}
+
+func TestRejection(t *testing.T) {
+	proposals := []Distribution{
+		UniDistAB{-math.Pi, math.Pi},
+		TruncatedNormalDist{0, 1.5, -math.Pi, math.Pi},
+	}
+
+	for _, proposal := range proposals {
+		r, err := NewRejection(cosSquaredDist{}, proposal, 0, Seed())
+		if err != nil {
+			t.Fatal(err)
+		}
+		helperTestHistogram(r, -math.Pi, math.Pi, t)
+
+		// Acceptance rate should approximately be 1 / C
+		stats := r.Stats()
+		if math.Abs(stats.AcceptanceRate()*r.C()-1) > 0.05 {
+			t.Error(fmt.Sprintf("acceptance rate %v does not match envelope %v", stats.AcceptanceRate(), r.C()))
+		}
+
+		// Usable to compute expectations concurrently
+		e := Expectation{
+			Distribution: r,
+			Function:     func(x float64) float64 { return x * x },
+			Seeds:        Noise(16),
+		}
+		res := e.Refine(10000, 16)
+		if exp := math.Pi*math.Pi/3 - 2; math.Abs(res.Value-exp) > eps_exp {
+			t.Error(fmt.Sprintf("(value) %v is not approximately %v", res.Value, exp))
+		}
+	}
+
+	// Envelope grows, if the given constant is too small
+	r, err := NewRejection(cosSquaredDist{}, UniDistAB{-math.Pi, math.Pi}, 1, Seed())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 1000; i++ {
+		r.Sample()
+	}
+	if r.C() < 2 {
+		t.Error(fmt.Sprintf("envelope %v should have been raised above 2", r.C()))
+	}
+
+	if _, err := NewRejection(nil, UniDist{}, 0, Seed()); err == nil {
+		t.Error("should reject missing target")
+	}
+}
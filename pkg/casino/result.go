@@ -1,6 +1,9 @@
 package casino
 
-import "time"
+import (
+	"math"
+	"time"
+)
 
 // Holds statistics on the results
 // of a complete computation.
@@ -8,8 +11,65 @@ type Stats struct {
 	// These are totaled over all run
 	// experiments
 	Burn, Trials int
-	// Time taken for computation
-	Time time.Time
+	// Number of accepted trials, for
+	// samplers which reject proposals
+	Accepted int
+	// Wall time taken for the computation
+	Time time.Duration
+	// Wall time every sampler (or worker, for
+	// schemes without samplers) spent computing,
+	// totaled over all runs. This includes time
+	// the go routine was blocked or descheduled,
+	// so it only approximates CPU time. For an
+	// Expectation there is one entry per sampler,
+	// which may exceed the workers of the last run.
+	BusyTime []time.Duration
+	// Number of trials per second of
+	// wall time
+	Rate float64
+}
+
+// TotalBusyTime returns the total time spent
+// computing by all samplers or workers, see
+// BusyTime.
+func (s Stats) TotalBusyTime() time.Duration {
+	var total time.Duration
+	for _, t := range s.BusyTime {
+		total += t
+	}
+	return total
+}
+
+// Accumulates the wall time of a computation
+// and the busy time of its workers
+type timing struct {
+	wall    time.Duration
+	workers []time.Duration
+}
+
+func (t *timing) grow(workers int) {
+	for len(t.workers) < workers {
+		t.workers = append(t.workers, 0)
+	}
+}
+
+// Fill in the timing of s, once its
+// Trials are known
+func (t *timing) fill(s *Stats) {
+	s.Time = t.wall
+	s.BusyTime = append([]time.Duration(nil), t.workers...)
+	if t.wall > 0 {
+		s.Rate = float64(s.Trials) / t.wall.Seconds()
+	}
+}
+
+// AcceptanceRate returns the fraction
+// of trials which were accepted.
+func (s Stats) AcceptanceRate() float64 {
+	if s.Trials == 0 {
+		return 0
+	}
+	return float64(s.Accepted) / float64(s.Trials)
 }
 
 // Contains a single scalar
@@ -17,5 +77,102 @@ type Stats struct {
 type Result struct {
 	Value    float64
 	Variance float64
+	// Standard error of Value, assuming
+	// independent samples
+	StdErr float64
+	// Standard error of Value, estimated
+	// from batch means. Unlike StdErr, this
+	// accounts for correlated samples (e.g.
+	// from a Markov chain).
+	BatchStdErr float64
+	// Integrated autocorrelation time, which
+	// is about 1 for independent samples, and
+	// the effective number of independent
+	// samples (Trials / Tau)
+	Tau, ESS float64
+	// Factor by which variance reduction
+	// (e.g. antithetic pairs or control
+	// variates) lowered the variance per
+	// function evaluation. This is 0 if
+	// no variance reduction was used.
+	Reduction float64
 	Stats
 }
+
+// Interval returns a confidence interval for
+// Value at the given level (e.g. 0.95), based
+// on BatchStdErr and the central limit theorem.
+func (r Result) Interval(level float64) (float64, float64) {
+	z := stdNormalInv(0.5 + 0.5*level)
+	return r.Value - z*r.BatchStdErr, r.Value + z*r.BatchStdErr
+}
+
+// Maximal number of batches kept per stream
+const maxBatches = 64
+
+// batchMeans accumulates the means of
+// consecutive batches from a single stream
+// of samples. Once maxBatches batches are
+// complete, neighbouring batches are merged
+// and the batch size doubles, so the batches
+// grow with the length of the stream.
+type batchMeans struct {
+	size  int
+	sums  []float64
+	sum   float64
+	count int
+}
+
+func (b *batchMeans) add(x float64) {
+	if b.size == 0 {
+		b.size = 1
+	}
+	b.sum += x
+	b.count++
+	if b.count < b.size {
+		return
+	}
+	b.sums = append(b.sums, b.sum)
+	b.sum, b.count = 0, 0
+	if len(b.sums) == maxBatches {
+		for i := 0; i < maxBatches/2; i++ {
+			b.sums[i] = b.sums[2*i] + b.sums[2*i+1]
+		}
+		b.sums = b.sums[:maxBatches/2]
+		b.size *= 2
+	}
+}
+
+// Fill in the error estimates of res from
+// the batches of all streams. Streams may
+// use different batch sizes.
+func (res *Result) estimateErrors(batches []batchMeans) {
+	res.StdErr = math.Sqrt(res.Variance / float64(res.Trials))
+	res.BatchStdErr, res.Tau, res.ESS = res.StdErr, 1, float64(res.Trials)
+
+	var n, k, sum float64
+	for _, b := range batches {
+		for _, s := range b.sums {
+			sum += s
+		}
+		n += float64(b.size * len(b.sums))
+		k += float64(len(b.sums))
+	}
+	if k < 2 || !(res.Variance > 0) {
+		return
+	}
+	// Variance of the batch means, scaled to
+	// the asymptotic variance of single samples
+	mean := sum / n
+	var asymVar float64
+	for _, b := range batches {
+		size := float64(b.size)
+		for _, s := range b.sums {
+			asymVar += size * (s/size - mean) * (s/size - mean)
+		}
+	}
+	asymVar /= k - 1
+	res.BatchStdErr = math.Sqrt(asymVar / float64(res.Trials))
+	res.Tau = asymVar / res.Variance
+	res.ESS = float64(res.Trials) / res.Tau
+}
@@ -0,0 +1,54 @@
+package rngtest
+
+import (
+	"math"
+
+	"github.com/dyedgreen/comp-phys/pkg/casino"
+)
+
+// Bins used by the chi-squared test in Battery
+const batteryBins = 50
+
+// Uniform deviates at the edges of [0, 1),
+// which are produced by the samplers with
+// small, but non-zero probability
+var edges = []float64{0, 0x1p-53, 1e-10, 0.5, 1 - 1e-10, 1 - 0x1p-53}
+
+// Finite checks that dist transforms deviates
+// at the edges of [0, 1) into finite values
+// within its support. The statistic is the
+// number of failures, and P is 0 if any value
+// fails and 1 otherwise.
+func Finite(dist casino.Distribution) Result {
+	min, max := dist.Support()
+	var failures float64
+	for _, u := range edges {
+		x := dist.Transform(u)
+		if math.IsNaN(x) || math.IsInf(x, 0) || x < min || x > max {
+			failures++
+		}
+	}
+	res := Result{Test: "finite transform", Statistic: failures, P: 1}
+	if failures > 0 {
+		res.P = 0
+	}
+	return res
+}
+
+// Battery draws n samples from s, and checks
+// them against s.Prob, using all tests of this
+// package, which are applicable to samplers.
+func Battery(s casino.Sampler, n int) []Result {
+	x := make([]float64, n)
+	for i := range x {
+		x[i] = s.Sample()
+	}
+	return []Result{
+		Finite(s),
+		ChiSquare(x, s, batteryBins),
+		KolmogorovSmirnov(x, s),
+		AndersonDarling(x, s),
+		Runs(x),
+		SerialCorrelation(x, 1),
+	}
+}
@@ -0,0 +1,166 @@
+package rngtest
+
+import (
+	"math"
+	"sort"
+
+	"github.com/dyedgreen/comp-phys/pkg/casino"
+	"gonum.org/v1/gonum/integrate/quad"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// Quadrature points for the tails and
+// between neighbouring samples
+const tailPoints = 256
+const gapPoints = 8
+
+// Probability integral transform, returns
+// the sorted values F(x_i), which are uniform
+// on [0, 1] if x is distributed according to
+// dist. If dist implements casino.CDFer, its
+// CDF is used. Otherwise the CDF is obtained
+// by integrating Prob between the sorted
+// samples, and is normalized by the total mass.
+func pit(x []float64, dist casino.Density) []float64 {
+	sorted := make([]float64, len(x))
+	copy(sorted, x)
+	sort.Float64s(sorted)
+	if len(sorted) == 0 {
+		return sorted
+	}
+	if cdf, ok := dist.(casino.CDFer); ok {
+		for i := range sorted {
+			sorted[i] = math.Min(math.Max(cdf.CDF(sorted[i]), 0), 1)
+		}
+		return sorted
+	}
+
+	min, max := dist.Support()
+	prob := func(x float64) float64 {
+		if x < min || x > max {
+			return 0
+		}
+		return dist.Prob(x)
+	}
+	integrate := func(a, b float64, n int) float64 {
+		// Samples outside of the support have
+		// no mass below or above them
+		a, b = math.Max(a, min), math.Min(b, max)
+		if !(a < b) {
+			return 0
+		}
+		return quad.Fixed(prob, a, b, n, nil, 0)
+	}
+
+	u := make([]float64, len(sorted))
+	u[0] = integrate(min, sorted[0], tailPoints)
+	for i := 1; i < len(sorted); i++ {
+		u[i] = u[i-1] + integrate(sorted[i-1], sorted[i], gapPoints)
+	}
+	total := u[len(u)-1] + integrate(sorted[len(sorted)-1], max, tailPoints)
+	for i := range u {
+		u[i] = math.Min(math.Max(u[i]/total, 0), 1)
+	}
+	return u
+}
+
+// ChiSquare performs Pearson's chi-squared test
+// with the given number of equiprobable bins.
+func ChiSquare(x []float64, dist casino.Density, bins int) Result {
+	u := pit(x, dist)
+	counts := make([]float64, bins)
+	for _, v := range u {
+		bin := int(v * float64(bins))
+		if bin == bins {
+			bin--
+		}
+		counts[bin]++
+	}
+	exp := float64(len(u)) / float64(bins)
+	var chi2 float64
+	for _, c := range counts {
+		chi2 += (c - exp) * (c - exp) / exp
+	}
+	return Result{
+		Test:      "chi-squared",
+		Statistic: chi2,
+		P:         distuv.ChiSquared{K: float64(bins - 1)}.Survival(chi2),
+	}
+}
+
+// KolmogorovSmirnov performs the Kolmogorov-Smirnov
+// test. The p-value uses the asymptotic distribution
+// with the finite sample correction from:
+//
+//     Stephens, M. A. (1970). "Use of the Kolmogorov-Smirnov, Cramer-Von Mises
+//     and Related Statistics Without Extensive Tables". J. R. Statist. Soc. B
+//     32 (1): 115–122.
+func KolmogorovSmirnov(x []float64, dist casino.Density) Result {
+	u := pit(x, dist)
+	n := float64(len(u))
+	var d float64
+	for i, v := range u {
+		d = math.Max(d, math.Max(float64(i+1)/n-v, v-float64(i)/n))
+	}
+	lambda := (math.Sqrt(n) + 0.12 + 0.11/math.Sqrt(n)) * d
+	return Result{
+		Test:      "Kolmogorov-Smirnov",
+		Statistic: d,
+		P:         kolmogorovQ(lambda),
+	}
+}
+
+// Survival function of the Kolmogorov distribution
+func kolmogorovQ(lambda float64) float64 {
+	if lambda < 0.2 {
+		return 1
+	}
+	var q, sign float64 = 0, 1
+	for k := 1; k <= 100; k++ {
+		term := sign * 2 * math.Exp(-2*float64(k*k)*lambda*lambda)
+		q += term
+		if math.Abs(term) < 1e-16 {
+			break
+		}
+		sign = -sign
+	}
+	return math.Min(math.Max(q, 0), 1)
+}
+
+// AndersonDarling performs the Anderson-Darling test,
+// which is more sensitive to the tails than the
+// Kolmogorov-Smirnov test. The p-value uses the
+// asymptotic distribution, as approximated in:
+//
+//     Marsaglia, G.; Marsaglia, J. (2004). "Evaluating the Anderson-Darling
+//     Distribution". Journal of Statistical Software. 9 (2): 1–5.
+func AndersonDarling(x []float64, dist casino.Density) Result {
+	u := pit(x, dist)
+	n := len(u)
+	// Guard the logarithms against u in {0, 1}
+	const eps = 1e-300
+	var s float64
+	for i := range u {
+		lo := math.Max(u[i], eps)
+		hi := math.Max(1-u[n-1-i], eps)
+		s += float64(2*i+1) * (math.Log(lo) + math.Log(hi))
+	}
+	a2 := -float64(n) - s/float64(n)
+	return Result{
+		Test:      "Anderson-Darling",
+		Statistic: a2,
+		P:         1 - andersonDarlingInf(a2),
+	}
+}
+
+// Asymptotic CDF of the Anderson-Darling statistic
+func andersonDarlingInf(z float64) float64 {
+	if z <= 0 {
+		return 0
+	}
+	if z < 2 {
+		return math.Exp(-1.2337141/z) / math.Sqrt(z) *
+			(2.00012 + (0.247105-(0.0649821-(0.0347962-(0.011672-0.00168691*z)*z)*z)*z)*z)
+	}
+	return math.Exp(-math.Exp(1.0776 - (2.30695-(0.43424-(0.082433-(0.008056-0.0003146*z)*z)*z)*z)*z))
+}
@@ -0,0 +1,5 @@
+// Package rngtest implements statistical tests, which check
+// that samplers produce their distributions and that random
+// sources are free of detectable structure. All tests report
+// p-values.
+package rngtest
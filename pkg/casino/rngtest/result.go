@@ -0,0 +1,23 @@
+package rngtest
+
+import "fmt"
+
+// Result holds the outcome of a single test.
+// Under the null hypothesis (e.g. samples are
+// distributed according to the given density),
+// P is uniformly distributed on [0, 1].
+type Result struct {
+	Test      string
+	Statistic float64
+	P         float64
+}
+
+// Pass reports if the test passes at
+// significance level alpha.
+func (r Result) Pass(alpha float64) bool {
+	return r.P >= alpha
+}
+
+func (r Result) String() string {
+	return fmt.Sprintf("%v: statistic %v, p-value %v", r.Test, r.Statistic, r.P)
+}
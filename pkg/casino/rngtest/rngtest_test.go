@@ -0,0 +1,109 @@
+package rngtest
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/dyedgreen/comp-phys/pkg/casino"
+	"golang.org/x/exp/rand"
+)
+
+const alpha = 1e-3
+
+// NormalDist before edge deviates were guarded
+type erfinvNormal struct {
+	casino.NormalDist
+}
+
+func (d erfinvNormal) Transform(x float64) float64 {
+	return math.Erfinv(2*x-1)*math.Sqrt2*d.Sigma + d.Mu
+}
+
+func TestBattery(t *testing.T) {
+	// Correct samplers pass
+	for i, s := range []casino.Sampler{
+		casino.NewSampler(casino.UniDist{}, casino.Seed()),
+		casino.NewSampler(casino.NormalDist{Mu: 1, Sigma: 2}, casino.Seed()),
+	} {
+		for _, res := range Battery(s, 10000) {
+			if !res.Pass(alpha) {
+				t.Error(fmt.Sprintf("sampler %v failed: %v", i, res))
+			}
+		}
+	}
+
+	// Samples from a different distribution fail
+	s := casino.NewSampler(casino.NormalDist{Mu: 0, Sigma: 1.1}, casino.Seed())
+	x := make([]float64, 10000)
+	for i := range x {
+		x[i] = s.Sample()
+	}
+	for _, res := range []Result{
+		ChiSquare(x, casino.NormalDist{Mu: 0, Sigma: 1}, 50),
+		KolmogorovSmirnov(x, casino.NormalDist{Mu: 0, Sigma: 1}),
+		AndersonDarling(x, casino.NormalDist{Mu: 0, Sigma: 1}),
+	} {
+		if res.Pass(alpha) {
+			t.Error(fmt.Sprintf("should fail: %v", res))
+		}
+	}
+
+	// Correlated samples fail
+	x[0] = 0
+	for i := 1; i < len(x); i++ {
+		x[i] = 0.5*x[i-1] + s.Sample()
+	}
+	for _, res := range []Result{Runs(x), SerialCorrelation(x, 1)} {
+		if res.Pass(alpha) {
+			t.Error(fmt.Sprintf("should fail: %v", res))
+		}
+	}
+
+	// Edge cases of the transform
+	if res := Finite(erfinvNormal{casino.NormalDist{Mu: 0, Sigma: 1}}); res.Pass(alpha) || res.Statistic != 1 {
+		t.Error(fmt.Sprintf("should fail: %v", res))
+	}
+}
+
+// P-values are uniform for correct samples
+func TestPValues(t *testing.T) {
+	const reps = 200
+	s := casino.NewSampler(casino.ExponentialDist{Lambda: 2}, casino.Seed())
+	var ks, ad []float64
+	x := make([]float64, 500)
+	for r := 0; r < reps; r++ {
+		for i := range x {
+			x[i] = s.Sample()
+		}
+		ks = append(ks, KolmogorovSmirnov(x, s).P)
+		ad = append(ad, AndersonDarling(x, s).P)
+	}
+	for _, p := range [][]float64{ks, ad} {
+		var mean float64
+		for _, v := range p {
+			mean += v / reps
+		}
+		// Uniform on [0, 1] has standard deviation 1 / sqrt(12)
+		if math.Abs(mean-0.5) > 4/math.Sqrt(12*reps) {
+			t.Error(fmt.Sprintf("mean p-value %v is not approximately 0.5", mean))
+		}
+	}
+}
+
+func TestBirthdaySpacings(t *testing.T) {
+	rng := rand.New(rand.NewSource(casino.Seed()))
+	if res := BirthdaySpacings(rng.Uint64, 200); !res.Pass(alpha) {
+		t.Error(fmt.Sprintf("PCG failed: %v", res))
+	}
+
+	// Weyl sequence has regular spacings
+	var state uint64
+	weyl := func() uint64 {
+		state += 0x9e3779b97f4a7c15
+		return state
+	}
+	if res := BirthdaySpacings(weyl, 200); res.Pass(alpha) {
+		t.Error(fmt.Sprintf("Weyl sequence should fail: %v", res))
+	}
+}
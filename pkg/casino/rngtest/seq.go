@@ -0,0 +1,70 @@
+package rngtest
+
+import (
+	"math"
+	"sort"
+)
+
+// Two-sided p-value of a standard normal statistic
+func normalP(z float64) float64 {
+	return math.Erfc(math.Abs(z) / math.Sqrt2)
+}
+
+// Runs performs the Wald-Wolfowitz runs test
+// for independence, counting the runs of samples
+// above and below the median.
+func Runs(x []float64) Result {
+	sorted := make([]float64, len(x))
+	copy(sorted, x)
+	sort.Float64s(sorted)
+	median := sorted[len(sorted)/2]
+
+	var runs, above, below float64
+	last := 0
+	for _, v := range x {
+		// Samples equal to the median are skipped
+		side := 0
+		if v > median {
+			side, above = 1, above+1
+		} else if v < median {
+			side, below = -1, below+1
+		}
+		if side != 0 && side != last {
+			runs++
+			last = side
+		}
+	}
+	n := above + below
+	mean := 2*above*below/n + 1
+	variance := (mean - 1) * (mean - 2) / (n - 1)
+	z := (runs - mean) / math.Sqrt(variance)
+	return Result{
+		Test:      "runs",
+		Statistic: runs,
+		P:         normalP(z),
+	}
+}
+
+// SerialCorrelation tests for vanishing
+// autocorrelation at the given lag. The
+// correlation coefficient is asymptotically
+// normal with variance 1/n.
+func SerialCorrelation(x []float64, lag int) Result {
+	var mean float64
+	for _, v := range x {
+		mean += v / float64(len(x))
+	}
+	var cov, variance float64
+	for i := range x {
+		variance += (x[i] - mean) * (x[i] - mean)
+		if i+lag < len(x) {
+			cov += (x[i] - mean) * (x[i+lag] - mean)
+		}
+	}
+	r := cov / variance
+	return Result{
+		Test:      "serial correlation",
+		Statistic: r,
+		P:         normalP(r * math.Sqrt(float64(len(x)-lag))),
+	}
+}
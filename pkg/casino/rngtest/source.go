@@ -0,0 +1,57 @@
+package rngtest
+
+import (
+	"math"
+	"sort"
+
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// Parameters of the birthday spacings test
+const birthdays = 512
+const birthdayBits = 24
+
+// BirthdaySpacings performs the birthday spacings
+// test on a raw source of random bits, as proposed
+// for the Diehard battery:
+//
+//     Marsaglia, G.; Tsang, W. W. (2002). "Some Difficult-to-pass Tests of
+//     Randomness". Journal of Statistical Software. 7 (3): 1–9.
+//
+// Every repetition draws 512 birthdays from the
+// top 24 bits of next, and counts the repeated
+// spacings between the sorted birthdays, which is
+// Poisson distributed with mean 2. The test uses
+// the total count over all repetitions.
+func BirthdaySpacings(next func() uint64, reps int) Result {
+	days := make([]uint64, birthdays)
+	spacings := make([]uint64, birthdays)
+	var total float64
+	for r := 0; r < reps; r++ {
+		for i := range days {
+			days[i] = next() >> (64 - birthdayBits)
+		}
+		sort.Slice(days, func(i, j int) bool { return days[i] < days[j] })
+		// Spacings wrap around the year
+		for i := 1; i < birthdays; i++ {
+			spacings[i] = days[i] - days[i-1]
+		}
+		spacings[0] = days[0] + 1<<birthdayBits - days[birthdays-1]
+		sort.Slice(spacings, func(i, j int) bool { return spacings[i] < spacings[j] })
+		for i := 1; i < birthdays; i++ {
+			if spacings[i] == spacings[i-1] {
+				total++
+			}
+		}
+	}
+
+	// Two sided Poisson p-value
+	lambda := float64(reps) * birthdays * birthdays * birthdays / (4 * (1 << birthdayBits))
+	poisson := distuv.Poisson{Lambda: lambda}
+	p := 2 * math.Min(poisson.CDF(total), 1-poisson.CDF(total-1))
+	return Result{
+		Test:      "birthday spacings",
+		Statistic: total,
+		P:         math.Min(p, 1),
+	}
+}
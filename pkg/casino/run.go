@@ -0,0 +1,185 @@
+package casino
+
+import (
+	"math"
+	"runtime"
+	"time"
+)
+
+// Trials per worker in the first refinement
+// of an adaptive run
+const minRunBatch = 64
+
+// StopReason tells why an adaptive
+// run of an Expectation stopped.
+type StopReason int
+
+const (
+	// The error target was met
+	Converged StopReason = iota
+	// The maximal number of trials
+	// was reached
+	MaxTrials
+	// The time budget was exhausted
+	TimeBudget
+)
+
+func (r StopReason) String() string {
+	switch r {
+	case Converged:
+		return "converged"
+	case MaxTrials:
+		return "maximal number of trials reached"
+	case TimeBudget:
+		return "time budget exhausted"
+	default:
+		return "unknown"
+	}
+}
+
+// Target specifies when an adaptive run stops.
+// The run converges, once all given error
+// targets are met, or stops when it exhausts
+// one of the budgets. Zero fields are ignored,
+// but at least one budget must be given.
+type Target struct {
+	// Targets for BatchStdErr, absolute and
+	// relative to the magnitude of Value
+	StdErr, RelErr float64
+	// Maximal number of trials (including
+	// trials from previous refinements)
+	MaxTrials int
+	// Maximal wall time of the run
+	Budget time.Duration
+}
+
+func (t Target) met(res Result) bool {
+	if t.StdErr <= 0 && t.RelErr <= 0 {
+		return false
+	}
+	return (t.StdErr <= 0 || res.BatchStdErr <= t.StdErr) &&
+		(t.RelErr <= 0 || res.BatchStdErr <= t.RelErr*math.Abs(res.Value))
+}
+
+// Trials needed to meet the target, estimated
+// from the error decreasing like 1/sqrt(n).
+func (t Target) needed(res Result) float64 {
+	ratio := 0.0
+	if t.StdErr > 0 {
+		ratio = math.Max(ratio, res.BatchStdErr/t.StdErr)
+	}
+	if t.RelErr > 0 {
+		ratio = math.Max(ratio, res.BatchStdErr/(t.RelErr*math.Abs(res.Value)))
+	}
+	return float64(res.Trials) * ratio * ratio
+}
+
+// Number of workers used by adaptive runs
+func (exp *Expectation) workers() int {
+	exp.lock.Lock()
+	defer exp.lock.Unlock()
+	exp.init()
+	n := len(exp.samplers)
+	if len(exp.Seeds) > n {
+		n = len(exp.Seeds)
+	}
+	if len(exp.Generators) > n {
+		n = len(exp.Generators)
+	}
+	if n == 0 && exp.Source != nil {
+		n = runtime.GOMAXPROCS(0)
+	}
+	return n
+}
+
+// Run refines the expectation until the target
+// is met or a budget is exhausted, and returns
+// the final result and why the run stopped.
+//
+// All available workers are used (one per seed
+// or generator, or GOMAXPROCS if only a Source
+// is given). The number of trials per refinement
+// is chosen from the current error estimate, such
+// that the run stops soon after the target is met.
+// The run will panic if no budget is given.
+func (exp *Expectation) Run(target Target) (Result, StopReason) {
+	if target.MaxTrials <= 0 && target.Budget <= 0 {
+		panic("need to provide a trial or time budget")
+	}
+
+	start := time.Now()
+	workers := exp.workers()
+	if workers == 0 {
+		panic("insufficient seeds, source or generators provided to run workers")
+	}
+	res := exp.Result()
+	batch := minRunBatch
+	var rate float64 // trials per second
+	for {
+		if res.Trials > 0 && target.met(res) {
+			return res, Converged
+		}
+
+		// Fit the next refinement into the budgets
+		if target.MaxTrials > 0 {
+			if left := (target.MaxTrials - res.Trials) / workers; left < batch {
+				batch = left
+			}
+			if batch < 1 {
+				return res, MaxTrials
+			}
+		}
+		if target.Budget > 0 {
+			left := (target.Budget - time.Since(start)).Seconds()
+			if left <= 0 {
+				return res, TimeBudget
+			}
+			if fit := int(left * rate / float64(workers)); rate > 0 && fit < batch {
+				batch = fit
+			}
+			if batch < 1 {
+				return res, TimeBudget
+			}
+		}
+
+		t0 := time.Now()
+		res = exp.Refine(batch, workers)
+		if elapsed := time.Since(t0).Seconds(); elapsed > 0 {
+			rate = float64(batch*workers) / elapsed
+		}
+
+		// Aim for the projected number of trials,
+		// growing the refinements at most 4 fold
+		next := (target.needed(res) - float64(res.Trials)) / float64(workers)
+		switch {
+		case !(next < float64(4*batch)):
+			batch *= 4
+		case next < minRunBatch:
+			batch = minRunBatch
+		default:
+			batch = int(math.Ceil(next))
+		}
+	}
+}
+
+// RunUntil refines the expectation until its
+// BatchStdErr drops below targetStdErr, or
+// maxTrials is reached. See Run.
+func (exp *Expectation) RunUntil(targetStdErr float64, maxTrials int) (Result, StopReason) {
+	return exp.Run(Target{StdErr: targetStdErr, MaxTrials: maxTrials})
+}
+
+// RunUntilRelative refines the expectation until
+// its BatchStdErr drops below targetRelErr times
+// the magnitude of its Value, or maxTrials is
+// reached. See Run.
+func (exp *Expectation) RunUntilRelative(targetRelErr float64, maxTrials int) (Result, StopReason) {
+	return exp.Run(Target{RelErr: targetRelErr, MaxTrials: maxTrials})
+}
+
+// RunFor refines the expectation until its
+// BatchStdErr drops below targetStdErr, or the
+// time budget is exhausted. See Run.
+func (exp *Expectation) RunFor(targetStdErr float64, budget time.Duration) (Result, StopReason) {
+	return exp.Run(Target{StdErr: targetStdErr, Budget: budget})
+}
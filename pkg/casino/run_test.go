@@ -0,0 +1,64 @@
+package casino
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestRun(t *testing.T) {
+	newExp := func() *Expectation {
+		return &Expectation{
+			Distribution: NormalDist{3, 2},
+			Function:     func(x float64) float64 { return x },
+			Seeds:        Noise(8),
+		}
+	}
+
+	// Absolute error, stops soon after target
+	exp := newExp()
+	res, reason := exp.RunUntil(1e-3, 1e9)
+	if reason != Converged || res.BatchStdErr > 1e-3 {
+		t.Error(fmt.Sprintf("should converge: %v (%v)", reason, res.BatchStdErr))
+	}
+	// Need about (2 / 1e-3)^2 = 4e6 trials
+	if res.Trials > 2e7 {
+		t.Error(fmt.Sprintf("took too many trials: %v", res.Trials))
+	}
+	if math.Abs(res.Value-3) > 5e-3 {
+		t.Error(fmt.Sprintf("(value) %v is not approximately 3", res.Value))
+	}
+
+	// Relative error
+	exp = newExp()
+	res, reason = exp.RunUntilRelative(1e-3, 1e9)
+	if reason != Converged || res.BatchStdErr > 1e-3*math.Abs(res.Value) {
+		t.Error(fmt.Sprintf("should converge: %v (%v)", reason, res.BatchStdErr))
+	}
+
+	// Trial budget is respected
+	exp = newExp()
+	res, reason = exp.RunUntil(1e-6, 100000)
+	if reason != MaxTrials || res.Trials > 100000 || res.Trials < 90000 {
+		t.Error(fmt.Sprintf("should run out of trials: %v (%v trials)", reason, res.Trials))
+	}
+
+	// Time budget is respected
+	exp = newExp()
+	start := time.Now()
+	res, reason = exp.RunFor(1e-9, 100*time.Millisecond)
+	if elapsed := time.Since(start); reason != TimeBudget || elapsed > 500*time.Millisecond {
+		t.Error(fmt.Sprintf("should run out of time: %v (%v)", reason, elapsed))
+	}
+
+	// Source only uses GOMAXPROCS workers
+	exp = &Expectation{
+		Distribution: UniDist{},
+		Function:     func(x float64) float64 { return x },
+		Source:       NewSeedSource(Seed()),
+	}
+	if _, reason = exp.RunUntil(1e-3, 1e9); reason != Converged {
+		t.Error(fmt.Sprintf("should converge: %v", reason))
+	}
+}
@@ -0,0 +1,89 @@
+package casino
+
+import (
+	"sync/atomic"
+
+	"golang.org/x/exp/rand"
+)
+
+// Increment of the SplitMix64 state (golden ratio)
+const splitMixGamma = 0x9e3779b97f4a7c15
+
+// SeedSource derives an unlimited number of
+// reproducible, statistically independent
+// seeds from a single master seed.
+//
+// Child seeds are the outputs of a SplitMix64
+// generator, so that even adjacent master seeds
+// (e.g. 1, 2, 3) lead to well separated PCG
+// streams. Sources can be split, to hand every
+// component of a computation its own sub-tree
+// of seeds.
+//
+// A SeedSource is safe for concurrent use. The
+// seeds drawn by concurrent callers are distinct,
+// but their assignment to callers depends on
+// scheduling; draw seeds up front if the mapping
+// of seeds to workers should be reproducible.
+type SeedSource struct {
+	state uint64
+}
+
+// NewSeedSource creates a SeedSource with
+// the given master seed.
+func NewSeedSource(seed uint64) *SeedSource {
+	// Mix the master seed, so sources with
+	// similar seeds do not share streams
+	return &SeedSource{splitMix64(&seed)}
+}
+
+// Seed returns the next child seed.
+func (s *SeedSource) Seed() uint64 {
+	z := atomic.AddUint64(&s.state, splitMixGamma)
+	return mix64(z)
+}
+
+// Seeds returns n child seeds.
+func (s *SeedSource) Seeds(n int) []uint64 {
+	res := make([]uint64, n)
+	for i := range res {
+		res[i] = s.Seed()
+	}
+	return res
+}
+
+// Split returns a new, independent SeedSource
+// seeded from this source.
+func (s *SeedSource) Split() *SeedSource {
+	return NewSeedSource(s.Seed())
+}
+
+// Rand returns a new PCG generator, seeded with
+// the next child seed. The generator is not safe
+// for concurrent use; every worker should obtain
+// its own.
+func (s *SeedSource) Rand() *rand.Rand {
+	return rand.New(rand.NewSource(s.Seed()))
+}
+
+// Sampler returns a new Sampler for dist, seeded
+// with the next child seed.
+func (s *SeedSource) Sampler(dist Distribution) Sampler {
+	return NewSampler(dist, s.Seed())
+}
+
+// SplitMix64 generator, as presented in:
+//
+//     Steele, G. L.; Lea, D.; Flood, C. H. (2014). "Fast Splittable
+//     Pseudorandom Number Generators". OOPSLA '14: 453–472.
+func splitMix64(state *uint64) uint64 {
+	*state += splitMixGamma
+	return mix64(*state)
+}
+
+// SplitMix64 output function
+func mix64(z uint64) uint64 {
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
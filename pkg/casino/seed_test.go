@@ -0,0 +1,88 @@
+package casino
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"testing"
+)
+
+func TestSeedSource(t *testing.T) {
+	// Reproducible
+	a, b := NewSeedSource(1).Seeds(10), NewSeedSource(1).Seeds(10)
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatal(fmt.Sprintf("seeds %v and %v differ", a, b))
+		}
+	}
+
+	// Adjacent master seeds and split sources
+	// produce distinct seeds
+	src := NewSeedSource(1)
+	sources := []*SeedSource{src, NewSeedSource(2), NewSeedSource(3), src.Split(), src.Split()}
+	seen := make(map[uint64]bool)
+	for _, s := range sources {
+		for _, seed := range s.Seeds(1000) {
+			if seen[seed] {
+				t.Fatal(fmt.Sprintf("seed %v drawn twice", seed))
+			}
+			seen[seed] = true
+		}
+	}
+
+	// Concurrent use does not produce duplicates
+	src = NewSeedSource(Seed())
+	const workers, n = 16, 1000
+	seeds := make([][]uint64, workers)
+	wait := sync.WaitGroup{}
+	wait.Add(workers)
+	for i := range seeds {
+		go func(i int) {
+			defer wait.Done()
+			for j := 0; j < n; j++ {
+				seeds[i] = append(seeds[i], src.Seed())
+			}
+		}(i)
+	}
+	wait.Wait()
+	seen = make(map[uint64]bool)
+	for i := range seeds {
+		for _, seed := range seeds[i] {
+			if seen[seed] {
+				t.Fatal(fmt.Sprintf("seed %v drawn twice concurrently", seed))
+			}
+			seen[seed] = true
+		}
+	}
+
+	// Streams from adjacent seeds are uncorrelated
+	x, y := NewSeedSource(7).Rand(), NewSeedSource(8).Rand()
+	var corr float64
+	const m = 100000
+	for i := 0; i < m; i++ {
+		corr += (x.Float64() - 0.5) * (y.Float64() - 0.5) * 12 / m
+	}
+	if math.Abs(corr) > 5/math.Sqrt(m) {
+		t.Error(fmt.Sprintf("streams are correlated: %v", corr))
+	}
+}
+
+func TestExpectationSource(t *testing.T) {
+	// Workers are created as needed
+	e := Expectation{
+		Distribution: UniDist{},
+		Function:     func(x float64) float64 { return x },
+		Source:       NewSeedSource(Seed()),
+	}
+	e.Refine(1000, 4)
+	res := e.Refine(1000, 64)
+	if res.Trials != 1000*68 {
+		t.Error(fmt.Sprintf("wrong number of trials %v", res.Trials))
+	}
+	if math.Abs(res.Value-0.5) > eps_exp {
+		t.Error(fmt.Sprintf("(value) %v is not approximately 0.5", res.Value))
+	}
+	if math.Abs(res.Variance-1.0/12.0) > eps_var {
+		t.Error(fmt.Sprintf("(variance) %v is not approximately %v", res.Variance, 1.0/12.0))
+	}
+}
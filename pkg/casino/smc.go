@@ -0,0 +1,452 @@
+package casino
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/exp/rand"
+)
+
+// Defaults for SMC samplers
+const defaultSMCParticles = 1000
+const defaultSMCThreshold = 0.5
+const defaultSMCRetain = 0.9
+const defaultSMCMoves = 5
+
+// Resampling selects a resampling scheme
+// for sequential Monte Carlo.
+type Resampling int
+
+const (
+	// Draw N independent ancestors
+	Multinomial Resampling = iota
+	// Use a single uniform deviate for
+	// N evenly spaced points
+	Systematic
+	// Keep floor(N W_i) copies and draw the
+	// rest from the residual weights
+	Residual
+)
+
+func (r Resampling) String() string {
+	switch r {
+	case Multinomial:
+		return "multinomial"
+	case Systematic:
+		return "systematic"
+	case Residual:
+		return "residual"
+	default:
+		return "unknown"
+	}
+}
+
+// Returns the ancestor indices obtained by
+// resampling the normalized weights w into
+// len(dst) particles.
+func (r Resampling) resample(dst []int, w []float64, rng *rand.Rand) {
+	n := len(dst)
+	cum := make([]float64, len(w))
+	var sum float64
+	for i := range w {
+		sum += w[i]
+		cum[i] = sum
+	}
+	// Guard against rounding in the last bin
+	pick := func(u float64) int {
+		i := sort.SearchFloat64s(cum, u*sum)
+		if i >= len(w) {
+			i = len(w) - 1
+		}
+		return i
+	}
+	switch r {
+	case Systematic:
+		u := rng.Float64()
+		for k := range dst {
+			dst[k] = pick((float64(k) + u) / float64(n))
+		}
+	case Residual:
+		k := 0
+		res := make([]float64, len(w))
+		for i := range w {
+			copies := int(math.Floor(float64(n) * w[i] / sum))
+			for c := 0; c < copies; c++ {
+				dst[k] = i
+				k++
+			}
+			res[i] = float64(n)*w[i]/sum - float64(copies)
+		}
+		if k < n {
+			Multinomial.resample(dst[k:], res, rng)
+		}
+	default:
+		for k := range dst {
+			dst[k] = pick(rng.Float64())
+		}
+	}
+}
+
+// SMCStats holds statistics on a
+// sequential Monte Carlo run.
+type SMCStats struct {
+	// Trials and Accepted count the
+	// rejuvenation moves
+	Stats
+	// Number of temperatures and of
+	// resampling steps, totaled over
+	// all replicas
+	Steps, Resamples int
+}
+
+func (s SMCStats) String() string {
+	return fmt.Sprintf("%v temperatures, %v resamples, %v moves (acceptance %v) in %v",
+		s.Steps, s.Resamples, s.Trials, s.AcceptanceRate(), s.Time)
+}
+
+// SMC implements a sequential Monte Carlo
+// sampler, which moves a population of
+// weighted particles from the Prior to the
+// target Pi along the tempered path:
+//
+//     pi_b(x) ~ prior(x)^(1-b) pi(x)^b
+//
+// for 0 <= b <= 1. After every reweighting,
+// the particles are resampled if their
+// effective sample size falls below Threshold
+// and rejuvenated with Moves steps of a random
+// walk Metropolis kernel, whose width is set
+// from the spread of the particles, see:
+//
+//     Del Moral, P.; Doucet, A.; Jasra, A. (2006). "Sequential Monte Carlo
+//     Samplers". J. R. Statist. Soc. B 68 (3): 411–436.
+//
+// Like APIS, the sampler estimates:
+//
+//     I = 1/Z \int f(x) pi(x) dx
+//
+// and
+//
+//     Z = \int pi(x) dx
+//
+// Tempering lets particles populate separated
+// modes while the target is still flat, which
+// makes the sampler robust to multimodal targets.
+//
+// Every seed runs an independent replica of the
+// particle population concurrently. The spread of
+// the replicas gives the variance of the estimates,
+// so at least two replicas are required. The Prior
+// must be normalized.
+type SMC struct {
+	// Functions used in estimation
+	Function, Pi func(float64) float64
+	// Initial distribution of the particles
+	Prior Distribution
+	// Number of particles per replica
+	// (defaults to 1000)
+	Particles int
+	// Increasing schedule of temperatures,
+	// ending at 1. If this is nil, the next
+	// temperature is chosen such that the
+	// effective sample size shrinks by the
+	// factor Retain (defaults to 0.9).
+	Temperatures []float64
+	Retain       float64
+	// Resampling scheme and fraction of the
+	// effective sample size below which the
+	// particles are resampled (defaults to 0.5)
+	Resampling Resampling
+	Threshold  float64
+	// Number of Metropolis moves per temperature
+	// (defaults to 5, negative values disable
+	// rejuvenation)
+	Moves int
+	// Seeds for the replicas, or a Source
+	// to draw the seeds of Replicas replicas
+	// from (at least two).
+	Seeds    []uint64
+	Source   *SeedSource
+	Replicas int
+
+	stats SMCStats
+}
+
+// A single replica of the particle population
+type smcReplica struct {
+	*SMC
+	rng             *rand.Rand
+	x, w            []float64
+	logPrior, logPi []float64
+	beta, logZ      float64
+	stats           SMCStats
+}
+
+// Normalize the weights, returning the log of
+// their sum and the effective sample size.
+func normalizeLogWeights(w, logW []float64) (float64, float64) {
+	max := math.Inf(-1)
+	for i := range logW {
+		max = math.Max(max, logW[i])
+	}
+	var sum, sum2 float64
+	for i := range logW {
+		w[i] = math.Exp(logW[i] - max)
+		sum += w[i]
+	}
+	for i := range w {
+		w[i] /= sum
+		sum2 += w[i] * w[i]
+	}
+	return max + math.Log(sum), 1 / sum2
+}
+
+// Incremental log weights for moving to beta
+func (r *smcReplica) increment(logW []float64, beta float64) {
+	for i := range r.x {
+		logW[i] = math.Log(r.w[i])
+		if d := beta - r.beta; d > 0 {
+			logW[i] += d * (r.logPi[i] - r.logPrior[i])
+		}
+	}
+}
+
+// Choose the next temperature by bisection
+func (r *smcReplica) next(logW, w []float64, step int) float64 {
+	if r.Temperatures != nil {
+		return r.Temperatures[step]
+	}
+	var sum2 float64
+	for i := range r.w {
+		sum2 += r.w[i] * r.w[i]
+	}
+	target := r.Retain / sum2
+	r.increment(logW, 1)
+	if _, ess := normalizeLogWeights(w, logW); ess >= target {
+		return 1
+	}
+	lo, hi := r.beta, 1.0
+	for k := 0; k < 50; k++ {
+		mid := 0.5 * (lo + hi)
+		r.increment(logW, mid)
+		if _, ess := normalizeLogWeights(w, logW); ess >= target {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	// Always make progress
+	return math.Max(lo, r.beta+1e-12)
+}
+
+// Log of the tempered density
+func (r *smcReplica) logTempered(logPrior, logPi float64) float64 {
+	if r.beta == 0 {
+		return logPrior
+	}
+	if r.beta == 1 {
+		return logPi
+	}
+	return (1-r.beta)*logPrior + r.beta*logPi
+}
+
+// Rejuvenate the particles with random walk
+// Metropolis moves targeting pi_beta.
+func (r *smcReplica) move() {
+	// Width from the weighted spread (2.38 is
+	// optimal for Gaussian targets)
+	var mean, sq float64
+	for i := range r.x {
+		mean += r.w[i] * r.x[i]
+		sq += r.w[i] * r.x[i] * r.x[i]
+	}
+	width := 2.38 * math.Sqrt(math.Max(sq-mean*mean, 0))
+	if !(width > 0) {
+		return
+	}
+	for m := 0; m < r.Moves; m++ {
+		for i := range r.x {
+			y := r.x[i] + width*r.rng.NormFloat64()
+			logPrior, logPi := math.Log(r.Prior.Prob(y)), math.Log(r.Pi(y))
+			ratio := r.logTempered(logPrior, logPi) - r.logTempered(r.logPrior[i], r.logPi[i])
+			r.stats.Trials++
+			if math.Log(r.rng.Float64()) < ratio {
+				r.x[i], r.logPrior[i], r.logPi[i] = y, logPrior, logPi
+				r.stats.Accepted++
+			}
+		}
+	}
+}
+
+func (r *smcReplica) run() {
+	n := r.Particles
+	r.x, r.w = make([]float64, n), make([]float64, n)
+	r.logPrior, r.logPi = make([]float64, n), make([]float64, n)
+	for i := range r.x {
+		r.x[i] = r.Prior.Transform(r.rng.Float64())
+		r.w[i] = 1 / float64(n)
+		r.logPrior[i], r.logPi[i] = math.Log(r.Prior.Prob(r.x[i])), math.Log(r.Pi(r.x[i]))
+	}
+
+	logW := make([]float64, n)
+	w := make([]float64, n)
+	ancestors := make([]int, n)
+	tmp := make([]float64, 3*n)
+	for step := 0; r.beta < 1; step++ {
+		beta := r.next(logW, w, step)
+		r.increment(logW, beta)
+		// Weights are normalized, so the sum of the
+		// reweighted particles is the ratio Z_b' / Z_b
+		logSum, ess := normalizeLogWeights(r.w, logW)
+		r.logZ += logSum
+		r.beta = beta
+		r.stats.Steps++
+
+		if ess < r.Threshold*float64(n) {
+			r.Resampling.resample(ancestors, r.w, r.rng)
+			copy(tmp[:n], r.x)
+			copy(tmp[n:2*n], r.logPrior)
+			copy(tmp[2*n:], r.logPi)
+			for i, a := range ancestors {
+				r.x[i], r.logPrior[i], r.logPi[i] = tmp[a], tmp[n+a], tmp[2*n+a]
+				r.w[i] = 1 / float64(n)
+			}
+			r.stats.Resamples++
+		}
+		r.move()
+	}
+}
+
+// Estimate returns estimates for I and Z
+// based on the SMC sampler.
+//
+// Z is the mean of the (unbiased) estimates
+// of the replicas, and I is their ratio
+// estimate weighted by Z. The variance of I
+// is obtained with the delta method and its
+// ESS accounts for the weight degeneracy of
+// the particles.
+func (smc *SMC) Estimate() (I, Z Result, err error) {
+	start := time.Now()
+	seeds := smc.Seeds
+	if seeds == nil && smc.Source != nil {
+		seeds = smc.Source.Seeds(smc.Replicas)
+	}
+	switch {
+	case smc.Function == nil || smc.Pi == nil || smc.Prior == nil:
+		return I, Z, errors.New("need to provide Function, Pi, and Prior")
+	case len(seeds) < 2:
+		// The replicas are the independent samples
+		// of the variance estimates
+		return I, Z, errors.New("need to provide seeds or a source for at least two replicas")
+	}
+	for i, b := range smc.Temperatures {
+		if b <= 0 || b > 1 || (i > 0 && b <= smc.Temperatures[i-1]) || (i == len(smc.Temperatures)-1 && b != 1) {
+			return I, Z, errors.New("temperatures must increase from above 0 to 1")
+		}
+	}
+	// Resolve defaults without changing the
+	// caller's configuration
+	config := *smc
+	if config.Particles < 1 {
+		config.Particles = defaultSMCParticles
+	}
+	if config.Threshold <= 0 || config.Threshold > 1 {
+		config.Threshold = defaultSMCThreshold
+	}
+	if config.Retain <= 0 || config.Retain >= 1 {
+		config.Retain = defaultSMCRetain
+	}
+	if config.Moves == 0 {
+		config.Moves = defaultSMCMoves
+	}
+
+	// Run replicas concurrently
+	replicas := make([]smcReplica, len(seeds))
+	var timing timing
+	timing.grow(len(replicas))
+	wait := sync.WaitGroup{}
+	wait.Add(len(replicas))
+	for k := range replicas {
+		replicas[k] = smcReplica{SMC: &config, rng: rand.New(rand.NewSource(seeds[k]))}
+		go func(k int, r *smcReplica) {
+			defer wait.Done()
+			t0 := time.Now()
+			r.run()
+			timing.workers[k] = time.Since(t0)
+		}(k, &replicas[k])
+	}
+	wait.Wait()
+
+	// Replica estimates, Z relative to the
+	// largest to avoid overflow
+	smc.stats = SMCStats{}
+	zs, is := make([]float64, len(replicas)), make([]float64, len(replicas))
+	fs := make([][]float64, len(replicas))
+	maxLogZ := math.Inf(-1)
+	for k := range replicas {
+		maxLogZ = math.Max(maxLogZ, replicas[k].logZ)
+	}
+	var sumZ, sumZI float64
+	for k, r := range replicas {
+		zs[k] = math.Exp(r.logZ - maxLogZ)
+		fs[k] = make([]float64, len(r.x))
+		for i := range r.x {
+			fs[k][i] = smc.Function(r.x[i])
+			is[k] += r.w[i] * fs[k][i]
+		}
+		sumZ += zs[k]
+		sumZI += zs[k] * is[k]
+
+		smc.stats.Trials += r.stats.Trials
+		smc.stats.Accepted += r.stats.Accepted
+		smc.stats.Steps += r.stats.Steps
+		smc.stats.Resamples += r.stats.Resamples
+	}
+
+	m := float64(len(replicas))
+	n := m * float64(config.Particles)
+	stats := Stats{Trials: int(n)}
+	timing.wall = time.Since(start)
+	timing.fill(&stats)
+	timing.fill(&smc.stats.Stats)
+	scale := math.Exp(maxLogZ)
+
+	// Z from the spread of the replicas, the
+	// replicas are the independent samples
+	Z = Result{Value: sumZ / m * scale, Stats: stats}
+	var varZ float64
+	for k := range zs {
+		varZ += (zs[k] - sumZ/m) * (zs[k] - sumZ/m) / (m - 1)
+	}
+	Z.StdErr = math.Sqrt(varZ/m) * scale
+	Z.Variance = Z.StdErr * Z.StdErr * n
+	Z.BatchStdErr, Z.ESS, Z.Tau = Z.StdErr, m, n/m
+
+	// I is the ratio sum Z_k I_k / sum Z_k
+	I = Result{Value: sumZI / sumZ, Stats: stats}
+	var sq, varF float64
+	for k, r := range replicas {
+		sq += zs[k] * zs[k] * (is[k] - I.Value) * (is[k] - I.Value)
+		for i := range r.x {
+			d := fs[k][i] - I.Value
+			varF += zs[k] / sumZ * r.w[i] * d * d
+		}
+	}
+	I.StdErr = math.Sqrt(sq*m/(m-1)) / sumZ
+	I.Variance = I.StdErr * I.StdErr * n
+	I.BatchStdErr = I.StdErr
+	I.Tau = I.Variance / varF
+	I.ESS = n / I.Tau
+	return
+}
+
+// Stats returns statistics on the
+// last run of Estimate.
+func (smc *SMC) Stats() SMCStats {
+	return smc.stats
+}
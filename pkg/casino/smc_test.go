@@ -0,0 +1,142 @@
+package casino
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"golang.org/x/exp/rand"
+)
+
+func TestResampling(t *testing.T) {
+	w := []float64{0.1, 0.45, 0.05, 0.4}
+	rng := rand.New(rand.NewSource(Seed()))
+	for _, scheme := range []Resampling{Multinomial, Systematic, Residual} {
+		const n, reps = 100, 1000
+		counts := make([]float64, len(w))
+		dst := make([]int, n)
+		for r := 0; r < reps; r++ {
+			scheme.resample(dst, w, rng)
+			for _, a := range dst {
+				counts[a]++
+			}
+		}
+		// Resampling is unbiased
+		for i := range w {
+			if exp := w[i] * n * reps; math.Abs(counts[i]-exp) > 5*math.Sqrt(exp) {
+				t.Error(fmt.Sprintf("%v: %v copies of %v, expected %v", scheme, counts[i], i, exp))
+			}
+		}
+	}
+
+	// Systematic and residual resampling keep
+	// at least floor(N W_i) copies
+	for _, scheme := range []Resampling{Systematic, Residual} {
+		dst := make([]int, 20)
+		scheme.resample(dst, w, rng)
+		counts := make([]int, len(w))
+		for _, a := range dst {
+			counts[a]++
+		}
+		for i := range w {
+			if c := int(20 * w[i]); counts[i] < c {
+				t.Error(fmt.Sprintf("%v: %v copies of %v, expected at least %v", scheme, counts[i], i, c))
+			}
+		}
+	}
+}
+
+func TestSMC(t *testing.T) {
+	// Bimodal target with Z = 2, and well separated modes
+	pi := func(x float64) float64 {
+		return 2 * (0.3*NormalDist{-4, 0.5}.Prob(x) + 0.7*NormalDist{4, 0.5}.Prob(x))
+	}
+	functions := []func(float64) float64{
+		func(x float64) float64 { return x },
+		func(x float64) float64 {
+			if x > 0 {
+				return 1
+			}
+			return 0
+		},
+	}
+	exps := []float64{0.3*-4 + 0.7*4, 0.7}
+
+	for _, scheme := range []Resampling{Multinomial, Systematic, Residual} {
+		for k, fn := range functions {
+			smc := SMC{
+				Function:   fn,
+				Pi:         pi,
+				Prior:      NormalDist{0, 10},
+				Particles:  500,
+				Resampling: scheme,
+				Source:     NewSeedSource(Seed()),
+				Replicas:   16,
+			}
+			I, Z, err := smc.Estimate()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(I.Value-exps[k]) > 5*I.StdErr+1e-3 || I.StdErr > 0.1 {
+				t.Error(fmt.Sprintf("%v: I = %v (+/- %v) should be %v", scheme, I.Value, I.StdErr, exps[k]))
+			}
+			if math.Abs(Z.Value-2) > 5*Z.StdErr+1e-3 || Z.StdErr > 0.1 {
+				t.Error(fmt.Sprintf("%v: Z = %v (+/- %v) should be 2", scheme, Z.Value, Z.StdErr))
+			}
+			if I.Trials != 500*16 || I.ESS <= 0 {
+				t.Error(fmt.Sprintf("wrong statistics %v", I))
+			}
+			if stats := smc.Stats(); stats.Steps < 16 || stats.Resamples == 0 || stats.AcceptanceRate() == 0 {
+				t.Error(fmt.Sprintf("unexpected run %v", stats))
+			}
+		}
+	}
+
+	// Fixed schedule
+	smc := SMC{
+		Function:     functions[1],
+		Pi:           pi,
+		Prior:        NormalDist{0, 10},
+		Temperatures: []float64{0.01, 0.03, 0.1, 0.2, 0.4, 0.7, 1},
+		Seeds:        Noise(8),
+	}
+	I, Z, err := smc.Estimate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(I.Value-0.7) > 0.05 || math.Abs(Z.Value-2) > 0.2 {
+		t.Error(fmt.Sprintf("I = %v, Z = %v should be 0.7, 2", I.Value, Z.Value))
+	}
+	if len(I.BusyTime) != 8 || I.Time <= 0 || smc.Stats().Rate <= 0 {
+		t.Error(fmt.Sprintf("missing timing: %v, %v, %v", I.Time, I.BusyTime, smc.Stats()))
+	}
+	if smc.Stats().Steps != 7*8 {
+		t.Error(fmt.Sprintf("schedule not used: %v", smc.Stats()))
+	}
+
+	// Defaults are not written back, so repeated
+	// runs use the same configuration
+	if smc.Particles != 0 || smc.Moves != 0 || smc.Retain != 0 || smc.Threshold != 0 {
+		t.Error(fmt.Sprintf("configuration was changed: %v", smc))
+	}
+	if again, _, _ := smc.Estimate(); again.Value != I.Value {
+		t.Error(fmt.Sprintf("repeated run gives %v, should be %v", again.Value, I.Value))
+	}
+
+	smc.Temperatures = []float64{0.5, 0.2, 1}
+	if _, _, err := smc.Estimate(); err == nil {
+		t.Error("should reject invalid schedule")
+	}
+	smc = SMC{Function: functions[0], Pi: pi, Prior: NormalDist{0, 1}}
+	if _, _, err := smc.Estimate(); err == nil {
+		t.Error("should reject missing seeds")
+	}
+	smc.Seeds = []uint64{Seed()}
+	if _, _, err := smc.Estimate(); err == nil {
+		t.Error("should reject a single replica")
+	}
+	smc.Seeds, smc.Source, smc.Replicas = nil, NewSeedSource(Seed()), 1
+	if _, _, err := smc.Estimate(); err == nil {
+		t.Error("should reject a single replica")
+	}
+}
@@ -0,0 +1,151 @@
+package casino
+
+import (
+	"errors"
+	"math"
+
+	"github.com/dyedgreen/comp-phys/pkg/interpolate"
+)
+
+// Limit on the number of cells used to
+// tabulate a distribution.
+const maxTabulatedCells = 1 << 20
+
+type tabulatedDist struct {
+	prob       func(float64) float64
+	a, b, norm float64
+	cdf, inv   *interpolate.MonotoneRange
+}
+
+// NewTabulatedDist creates a distribution proportional to
+// prob, with support [a, b]. The density does not need to
+// be normalized, but must be non-negative and finite on
+// [a, b], and a, b must be finite.
+//
+// The CDF is tabulated with Simpson's rule on a grid, which
+// is refined until the monotone cubic interpolation of its
+// inverse satisfies |F(Transform(u)) - u| < tol. The table
+// is not built using quad, since quad depends on this package.
+func NewTabulatedDist(prob func(float64) float64, a, b, tol float64) (Distribution, error) {
+	if a >= b || math.IsInf(a, 0) || math.IsInf(b, 0) {
+		return nil, errors.New("invalid range")
+	}
+	if tol <= 0 {
+		return nil, errors.New("tolerance must be positive")
+	}
+
+	xs, cdf, err := tabulate(prob, a, b, 64)
+	if err != nil {
+		return nil, err
+	}
+	for n := 128; n <= maxTabulatedCells; n *= 2 {
+		fineXs, fineCDF, err := tabulate(prob, a, b, n)
+		if err != nil {
+			return nil, err
+		}
+		fine, err := newTabulatedDist(prob, fineXs, fineCDF)
+		if err != nil {
+			return nil, err
+		}
+		coarse, err := newTabulatedDist(prob, xs, cdf)
+		if err != nil {
+			return nil, err
+		}
+
+		// The fine table contains the mid points of the coarse
+		// table, where the interpolation error is largest.
+		var maxErr float64
+		for i := 1; i < len(fineXs); i += 2 {
+			u := fineCDF[i] / fine.norm
+			x, _ := coarse.inv.Eval(u)
+			fu, _ := fine.cdf.Eval(x)
+			maxErr = math.Max(maxErr, math.Abs(fu-u))
+		}
+		maxErr = math.Max(maxErr, math.Abs(fine.norm-coarse.norm)/fine.norm)
+		if maxErr < tol {
+			return fine, nil
+		}
+		xs, cdf = fineXs, fineCDF
+	}
+	return nil, errors.New("tabulated distribution did not converge")
+}
+
+// Computes the (un-normalized) CDF at n+1
+// evenly spaced points, using Simpson's rule
+// on every cell.
+func tabulate(prob func(float64) float64, a, b float64, n int) ([]float64, []float64, error) {
+	xs := make([]float64, n+1, n+1)
+	cdf := make([]float64, n+1, n+1)
+	h := (b - a) / float64(n)
+	xs[0] = a
+	left := prob(a)
+	for i := 1; i <= n; i++ {
+		xs[i] = a + h*float64(i)
+		mid, right := prob(xs[i]-h/2), prob(xs[i])
+		if left < 0 || mid < 0 || right < 0 {
+			return nil, nil, errors.New("the probability density can not be negative")
+		}
+		cdf[i] = cdf[i-1] + h/6*(left+4*mid+right)
+		left = right
+	}
+	if cdf[n] <= 0 || math.IsInf(cdf[n], 0) || math.IsNaN(cdf[n]) {
+		return nil, nil, errors.New("the probability density can not be normalized")
+	}
+	return xs, cdf, nil
+}
+
+// Build the interpolation ranges from a CDF table.
+// Flat parts of the CDF (where the density vanishes)
+// are collapsed for the inverse.
+func newTabulatedDist(prob func(float64) float64, xs, cdf []float64) (*tabulatedDist, error) {
+	norm := cdf[len(cdf)-1]
+	us := make([]float64, len(cdf), len(cdf))
+	for i := range cdf {
+		us[i] = cdf[i] / norm
+	}
+	us[len(us)-1] = 1
+
+	invUs, invXs := make([]float64, 0, len(us)), make([]float64, 0, len(xs))
+	for i := range us {
+		if len(invUs) > 0 && us[i] <= invUs[len(invUs)-1] {
+			if us[i] == 0 {
+				// Leading flat part, start at its end
+				invXs[0] = xs[i]
+			}
+			continue
+		}
+		invUs = append(invUs, us[i])
+		invXs = append(invXs, xs[i])
+	}
+
+	cdfRange, err := interpolate.NewMonotoneRange(xs, us)
+	if err != nil {
+		return nil, err
+	}
+	invRange, err := interpolate.NewMonotoneRange(invUs, invXs)
+	if err != nil {
+		return nil, err
+	}
+	return &tabulatedDist{prob, xs[0], xs[len(xs)-1], norm, cdfRange, invRange}, nil
+}
+
+func (d *tabulatedDist) Transform(x float64) float64 {
+	y, _ := d.inv.Eval(math.Max(0, math.Min(1, x)))
+	return y
+}
+
+func (d *tabulatedDist) Prob(x float64) float64 {
+	if x < d.a || x > d.b {
+		return 0
+	}
+	return d.prob(x) / d.norm
+}
+
+func (d *tabulatedDist) Support() (float64, float64) {
+	return d.a, d.b
+}
+
+func (d *tabulatedDist) CDF(x float64) float64 {
+	y, _ := d.cdf.Eval(math.Max(d.a, math.Min(d.b, x)))
+	return y
+}
@@ -0,0 +1,52 @@
+package casino
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestTabulatedDist(t *testing.T) {
+	// Target from assignment question 5
+	target := func(x float64) float64 {
+		return math.Cos(0.5*x) * math.Cos(0.5*x)
+	}
+	cdf := func(x float64) float64 {
+		return (x + math.Sin(x) + math.Pi) / (2 * math.Pi)
+	}
+
+	for _, tol := range []float64{1e-3, 1e-6, 1e-9} {
+		dist, err := NewTabulatedDist(target, -math.Pi, math.Pi, tol)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i <= 1000; i++ {
+			u := float64(i) / 1000
+			if x := dist.Transform(u); math.Abs(cdf(x)-u) > tol {
+				t.Error(fmt.Sprintf("F(Transform(%v)) = %v exceeds tolerance %v", u, cdf(x), tol))
+			}
+		}
+		if p := dist.Prob(0); math.Abs(p-1/math.Pi) > tol {
+			t.Error(fmt.Sprintf("density %v is not normalized, should be %v", p, 1/math.Pi))
+		}
+	}
+
+	// Density vanishing on part of the support
+	dist, err := NewTabulatedDist(func(x float64) float64 {
+		if x < 1.01 {
+			return 0
+		}
+		return 1
+	}, 0, 2, 1e-5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	helperTestHistogram(dist, 0, 2, t)
+
+	if _, err := NewTabulatedDist(func(_ float64) float64 { return -1 }, 0, 1, 1e-3); err == nil {
+		t.Error("should reject negative density")
+	}
+	if _, err := NewTabulatedDist(target, 0, math.Inf(1), 1e-3); err == nil {
+		t.Error("should reject infinite range")
+	}
+}
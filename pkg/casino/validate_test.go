@@ -0,0 +1,66 @@
+package casino_test
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"testing"
+
+	"github.com/dyedgreen/comp-phys/pkg/casino"
+	"github.com/dyedgreen/comp-phys/pkg/casino/rngtest"
+)
+
+// Validate the samples of every distribution
+// with the rngtest battery. The battery runs
+// about a hundred tests, so every distribution
+// uses a fixed seed derived from its name to
+// keep the validation deterministic.
+func TestValidateDistributions(t *testing.T) {
+	linear, err := casino.NewLinearDist(0, 2, -0.48, 0.98)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tabulated, err := casino.NewTabulatedDist(func(x float64) float64 {
+		return math.Exp(-x*x) * (1 + math.Sin(3*x)*math.Sin(3*x))
+	}, -4, 4, 1e-6)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mixture, err := casino.NewMixture([]float64{1, 2}, casino.NormalDist{Mu: -2, Sigma: 1}, casino.GammaDist{Alpha: 2, Beta: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	truncated, err := casino.NewTruncated(casino.NormalDist{Mu: 0, Sigma: 1}, 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dists := map[string]casino.Distribution{
+		"uniform":     casino.UniDistAB{A: -1, B: 3},
+		"linear":      linear,
+		"normal":      casino.NormalDist{Mu: 1, Sigma: 0.5},
+		"exponential": casino.ExponentialDist{Lambda: 2},
+		"cauchy":      casino.CauchyDist{X0: 1, Gamma: 2},
+		"laplace":     casino.LaplaceDist{Mu: -1, B: 0.5},
+		"student-t":   casino.StudentTDist{Nu: 3, Mu: 0, Sigma: 2},
+		"gamma":       casino.GammaDist{Alpha: 2.5, Beta: 1.5},
+		"beta":        casino.BetaDist{Alpha: 2, Beta: 5},
+		"log-normal":  casino.LogNormalDist{Mu: 0, Sigma: 0.5},
+		"weibull":     casino.WeibullDist{K: 1.5, Lambda: 2},
+		"truncated":   truncated,
+		"trunc-norm":  casino.TruncatedNormalDist{Mu: 0, Sigma: 1, A: 1, B: 3},
+		"tabulated":   tabulated,
+		"mixture":     mixture,
+	}
+	for name, dist := range dists {
+		h := fnv.New64a()
+		h.Write([]byte(name))
+		s := casino.NewSampler(dist, h.Sum64())
+		for _, res := range rngtest.Battery(s, 20000) {
+			if !res.Pass(1e-4) {
+				t.Error(fmt.Sprintf("%v: %v", name, res))
+			}
+		}
+	}
+}
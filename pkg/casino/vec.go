@@ -0,0 +1,312 @@
+package casino
+
+import (
+	"errors"
+	"math"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/mat"
+)
+
+// These are the multivariate counterparts
+// of Transformer, Prober, Supporter,
+// Distribution, Generator and Sampler.
+
+// TransformerVec takes len(u) == Dim()
+// uniform deviates and writes a value
+// distributed according to a probability
+// function into dst.
+type TransformerVec interface {
+	TransformVec(dst, u []float64)
+}
+
+// ProberVec calculates the probability
+// density.
+type ProberVec interface {
+	ProbVec(x []float64) float64
+}
+
+// SupporterVec returns the support of a
+// probability density.
+type SupporterVec interface {
+	SupportVec() Region
+}
+
+// DistributionVec is sufficient to sample
+// from a multivariate distribution.
+type DistributionVec interface {
+	Dim() int
+	TransformerVec
+	ProberVec
+	SupporterVec
+}
+
+// GeneratorVec produces a stream of
+// vector samples.
+type GeneratorVec interface {
+	Dim() int
+	SampleInto(dst []float64)
+}
+
+// SamplerVec is a GeneratorVec, which
+// samples from a known distribution.
+type SamplerVec interface {
+	DistributionVec
+	SampleInto(dst []float64)
+}
+
+// Region is a subset of R^n.
+type Region interface {
+	Dim() int
+	Contains(x []float64) bool
+}
+
+// Box is the region Min <= x <= Max
+// (element-wise). Bounds may be infinite.
+type Box struct {
+	Min, Max []float64
+}
+
+func (b Box) Dim() int {
+	return len(b.Min)
+}
+
+func (b Box) Contains(x []float64) bool {
+	for i := range x {
+		if x[i] < b.Min[i] || x[i] > b.Max[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// RealSpace returns the box covering R^n.
+func RealSpace(n int) Box {
+	b := Box{make([]float64, n), make([]float64, n)}
+	for i := 0; i < n; i++ {
+		b.Min[i], b.Max[i] = math.Inf(-1), math.Inf(+1)
+	}
+	return b
+}
+
+// Ellipsoid is the axis aligned region
+// sum_i ((x_i - Center_i) / Radii_i)^2 <= 1.
+type Ellipsoid struct {
+	Center, Radii []float64
+}
+
+func (e Ellipsoid) Dim() int {
+	return len(e.Center)
+}
+
+func (e Ellipsoid) Contains(x []float64) bool {
+	var r float64
+	for i := range x {
+		d := (x[i] - e.Center[i]) / e.Radii[i]
+		r += d * d
+	}
+	return r <= 1
+}
+
+// Simplex is the region x_i >= 0,
+// sum_i x_i = 1 (up to rounding).
+type Simplex struct {
+	N int
+}
+
+func (s Simplex) Dim() int {
+	return s.N
+}
+
+func (s Simplex) Contains(x []float64) bool {
+	var sum float64
+	for i := range x {
+		if x[i] < 0 {
+			return false
+		}
+		sum += x[i]
+	}
+	return math.Abs(sum-1) < 1e-10
+}
+
+// Sampler for multivariate distributions
+type samplerVec struct {
+	DistributionVec
+	rng *rand.Rand
+	u   []float64
+}
+
+// NewSamplerVec creates a new multivariate sampler,
+// see NewSampler.
+func NewSamplerVec(dist DistributionVec, seed uint64) SamplerVec {
+	return &samplerVec{dist, rand.New(rand.NewSource(seed)), make([]float64, dist.Dim())}
+}
+
+// SampleInto draws Dim() uniform deviates from
+// the underlying source and transforms them.
+func (s *samplerVec) SampleInto(dst []float64) {
+	for i := range s.u {
+		s.u[i] = s.rng.Float64()
+	}
+	s.TransformVec(dst, s.u)
+}
+
+// ProductDist implements the joint distribution
+// of independent components.
+type ProductDist []Distribution
+
+func (d ProductDist) Dim() int {
+	return len(d)
+}
+
+func (d ProductDist) TransformVec(dst, u []float64) {
+	for i := range d {
+		dst[i] = d[i].Transform(u[i])
+	}
+}
+
+func (d ProductDist) ProbVec(x []float64) float64 {
+	p := 1.0
+	for i := range d {
+		p *= d[i].Prob(x[i])
+	}
+	return p
+}
+
+func (d ProductDist) SupportVec() Region {
+	b := Box{make([]float64, len(d)), make([]float64, len(d))}
+	for i := range d {
+		b.Min[i], b.Max[i] = d[i].Support()
+	}
+	return b
+}
+
+// MultiNormalDist implements a multivariate
+// normal distribution. Samples are obtained
+// from independent standard normals using the
+// Cholesky decomposition of the covariance.
+type MultiNormalDist struct {
+	mu []float64
+	// Cholesky factor, row major (only the
+	// lower triangle is used)
+	l       []float64
+	logNorm float64
+}
+
+// Dimension up to which ProbVec needs no
+// heap allocated scratch space.
+const multiNormalStackDim = 16
+
+// NewMultiNormalDist creates a multivariate normal
+// distribution with mean mu and covariance sigma.
+// Sigma must be positive definite.
+func NewMultiNormalDist(mu []float64, sigma mat.Symmetric) (*MultiNormalDist, error) {
+	n := len(mu)
+	if sigma.Symmetric() != n {
+		return nil, errors.New("mean and covariance dimensions do not match")
+	}
+	d := &MultiNormalDist{mu: make([]float64, n), l: make([]float64, n*n)}
+	copy(d.mu, mu)
+	var chol mat.Cholesky
+	if ok := chol.Factorize(sigma); !ok {
+		return nil, errors.New("covariance must be positive definite")
+	}
+	var l mat.TriDense
+	chol.LTo(&l)
+	for i := 0; i < n; i++ {
+		for j := 0; j <= i; j++ {
+			d.l[i*n+j] = l.At(i, j)
+		}
+	}
+	d.logNorm = -0.5*float64(n)*math.Log(2*math.Pi) - 0.5*chol.LogDet()
+	return d, nil
+}
+
+func (d *MultiNormalDist) Dim() int {
+	return len(d.mu)
+}
+
+func (d *MultiNormalDist) TransformVec(dst, u []float64) {
+	n := len(d.mu)
+	for i := 0; i < n; i++ {
+		dst[i] = stdNormalInv(u[i])
+	}
+	// x = mu + L z, computed in place from the last
+	// row, which leaves z[j] for j <= i untouched
+	for i := n - 1; i >= 0; i-- {
+		x := d.mu[i]
+		row := d.l[i*n : i*n+i+1]
+		for j, l := range row {
+			x += l * dst[j]
+		}
+		dst[i] = x
+	}
+}
+
+func (d *MultiNormalDist) ProbVec(x []float64) float64 {
+	n := len(d.mu)
+	var buf [multiNormalStackDim]float64
+	y := buf[:]
+	if n > len(buf) {
+		y = make([]float64, n)
+	}
+	// Solve L y = x - mu by forward substitution,
+	// then |y|^2 is the Mahalanobis distance
+	var dist float64
+	for i := 0; i < n; i++ {
+		yi := x[i] - d.mu[i]
+		row := d.l[i*n : i*n+i]
+		for j, l := range row {
+			yi -= l * y[j]
+		}
+		yi /= d.l[i*n+i]
+		y[i] = yi
+		dist += yi * yi
+	}
+	return math.Exp(d.logNorm - 0.5*dist)
+}
+
+func (d *MultiNormalDist) SupportVec() Region {
+	return RealSpace(len(d.mu))
+}
+
+// DirichletDist implements a Dirichlet distribution
+// with concentration parameters Alpha. Samples are
+// obtained by normalizing independent Gamma deviates.
+type DirichletDist struct {
+	Alpha []float64
+}
+
+func (d DirichletDist) Dim() int {
+	return len(d.Alpha)
+}
+
+func (d DirichletDist) TransformVec(dst, u []float64) {
+	var sum float64
+	for i := range d.Alpha {
+		dst[i] = GammaDist{d.Alpha[i], 1}.Transform(u[i])
+		sum += dst[i]
+	}
+	for i := range dst {
+		dst[i] /= sum
+	}
+}
+
+func (d DirichletDist) ProbVec(x []float64) float64 {
+	if !(Simplex{len(d.Alpha)}).Contains(x) {
+		return 0
+	}
+	var logP, sumAlpha, logBeta float64
+	for i, a := range d.Alpha {
+		logP += (a - 1) * math.Log(x[i])
+		lg, _ := math.Lgamma(a)
+		logBeta += lg
+		sumAlpha += a
+	}
+	lg, _ := math.Lgamma(sumAlpha)
+	return math.Exp(logP - logBeta + lg)
+}
+
+func (d DirichletDist) SupportVec() Region {
+	return Simplex{len(d.Alpha)}
+}
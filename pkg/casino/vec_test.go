@@ -0,0 +1,142 @@
+package casino
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestRegions(t *testing.T) {
+	box := Box{[]float64{0, -1}, []float64{1, 1}}
+	if !box.Contains([]float64{0.5, 0}) || box.Contains([]float64{0.5, 2}) {
+		t.Error("box contains wrong points")
+	}
+	if !RealSpace(2).Contains([]float64{1e300, -1e300}) {
+		t.Error("real space should contain everything")
+	}
+	ell := Ellipsoid{[]float64{1, 1}, []float64{2, 1}}
+	if !ell.Contains([]float64{2.9, 1}) || ell.Contains([]float64{1, 2.1}) {
+		t.Error("ellipsoid contains wrong points")
+	}
+	simp := Simplex{3}
+	if !simp.Contains([]float64{0.2, 0.3, 0.5}) || simp.Contains([]float64{0.2, 0.3, 0.6}) || simp.Contains([]float64{-0.1, 0.6, 0.5}) {
+		t.Error("simplex contains wrong points")
+	}
+}
+
+func TestProductDist(t *testing.T) {
+	d := ProductDist{NormalDist{0, 1}, ExponentialDist{2}}
+	x := []float64{0.3, 0.7}
+	if exp := (NormalDist{0, 1}).Prob(0.3) * (ExponentialDist{2}).Prob(0.7); math.Abs(d.ProbVec(x)-exp) > 1e-15 {
+		t.Error(fmt.Sprintf("%v is not %v", d.ProbVec(x), exp))
+	}
+	if sup := d.SupportVec(); sup.Contains([]float64{0, -1}) || !sup.Contains([]float64{-5, 1}) {
+		t.Error(fmt.Sprintf("wrong support %v", sup))
+	}
+}
+
+func TestMultiNormalDist(t *testing.T) {
+	mu := []float64{1, -2}
+	sigma := mat.NewSymDense(2, []float64{
+		2, 0.6,
+		0.6, 0.5,
+	})
+	d, err := NewMultiNormalDist(mu, sigma)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Density matches closed form
+	det := 2*0.5 - 0.6*0.6
+	x := []float64{0, 0}
+	dx, dy := x[0]-mu[0], x[1]-mu[1]
+	maha := (0.5*dx*dx - 2*0.6*dx*dy + 2*dy*dy) / det
+	if exp := math.Exp(-0.5*maha) / (2 * math.Pi * math.Sqrt(det)); math.Abs(d.ProbVec(x)-exp) > 1e-12 {
+		t.Error(fmt.Sprintf("density %v is not %v", d.ProbVec(x), exp))
+	}
+
+	// Sample mean and covariance
+	const n = 100000
+	s := NewSamplerVec(d, Seed())
+	mean, cov := make([]float64, 2), make([]float64, 4)
+	for i := 0; i < n; i++ {
+		s.SampleInto(x)
+		for j := range x {
+			mean[j] += x[j] / n
+			for k := range x {
+				cov[2*j+k] += (x[j] - mu[j]) * (x[k] - mu[k]) / n
+			}
+		}
+	}
+	for j := range mu {
+		if math.Abs(mean[j]-mu[j]) > 0.02 {
+			t.Error(fmt.Sprintf("mean %v is not approximately %v", mean, mu))
+		}
+		for k := range mu {
+			if math.Abs(cov[2*j+k]-sigma.At(j, k)) > 0.05 {
+				t.Error(fmt.Sprintf("covariance %v is not approximately %v", cov, sigma))
+			}
+		}
+	}
+
+	// Sampling and density evaluation do not allocate
+	u := []float64{0.3, 0.8}
+	if allocs := testing.AllocsPerRun(100, func() {
+		d.TransformVec(x, u)
+		d.ProbVec(x)
+	}); allocs != 0 {
+		t.Error(fmt.Sprintf("%v allocations per sample", allocs))
+	}
+
+	// Dimensions beyond the stack scratch space
+	big := make([]float64, 2*multiNormalStackDim)
+	ident := mat.NewDiagDense(len(big), nil)
+	for i := range big {
+		ident.SetDiag(i, 4)
+		big[i] = 1
+	}
+	bd, err := NewMultiNormalDist(make([]float64, len(big)), ident)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := math.Exp(-0.5 * float64(len(big)) * (math.Log(8*math.Pi) + 0.25))
+	if p := bd.ProbVec(big); math.Abs(p/exp-1) > 1e-12 {
+		t.Error(fmt.Sprintf("density %v is not %v", p, exp))
+	}
+
+	if _, err := NewMultiNormalDist([]float64{0, 0}, mat.NewSymDense(2, []float64{1, 2, 2, 1})); err == nil {
+		t.Error("should reject indefinite covariance")
+	}
+	if _, err := NewMultiNormalDist([]float64{0}, sigma); err == nil {
+		t.Error("should reject mismatched dimensions")
+	}
+}
+
+func TestDirichletDist(t *testing.T) {
+	d := DirichletDist{[]float64{1, 2, 5}}
+	const n = 50000
+	s := NewSamplerVec(d, Seed())
+	x := make([]float64, 3)
+	mean := make([]float64, 3)
+	for i := 0; i < n; i++ {
+		s.SampleInto(x)
+		if !d.SupportVec().Contains(x) {
+			t.Fatal(fmt.Sprintf("sample %v outside of support", x))
+		}
+		for j := range x {
+			mean[j] += x[j] / n
+		}
+	}
+	for j, a := range d.Alpha {
+		if exp := a / 8; math.Abs(mean[j]-exp) > 0.01 {
+			t.Error(fmt.Sprintf("mean %v is not approximately %v", mean[j], exp))
+		}
+	}
+
+	// Uniform on the 2-simplex has density 2
+	if p := (DirichletDist{[]float64{1, 1, 1}}).ProbVec([]float64{0.2, 0.3, 0.5}); math.Abs(p-2) > 1e-12 {
+		t.Error(fmt.Sprintf("density %v is not 2", p))
+	}
+}
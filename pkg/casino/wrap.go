@@ -0,0 +1,197 @@
+package casino
+
+import (
+	"errors"
+	"math"
+
+	"gonum.org/v1/gonum/diff/fd"
+)
+
+// Distribution, which implements CDFer
+// using the given function.
+type withCDF struct {
+	Distribution
+	cdf func(float64) float64
+}
+
+func (d withCDF) CDF(x float64) float64 {
+	return d.cdf(x)
+}
+
+// Attach cdf to dist, if base implements CDFer,
+// so wrappers of wrappers keep the CDF.
+func attachCDF(base, dist Distribution, cdf func(CDFer, float64) float64) Distribution {
+	if c, ok := base.(CDFer); ok {
+		return withCDF{dist, func(x float64) float64 { return cdf(c, x) }}
+	}
+	return dist
+}
+
+// Reflect a deviate in [0, 1), so decreasing maps
+// keep Transform increasing, i.e. the inverse CDF.
+func reflect(x float64) float64 {
+	return math.Min(1-x, math.Nextafter(1, 0))
+}
+
+type affineDist struct {
+	dist         Distribution
+	shift, scale float64
+}
+
+// NewAffine returns the distribution of shift + scale * x,
+// where x is distributed according to dist. Scale must be
+// non-zero and finite. The result implements CDFer if
+// dist does.
+func NewAffine(dist Distribution, shift, scale float64) (Distribution, error) {
+	if dist == nil {
+		return nil, errors.New("need to provide a distribution")
+	}
+	if scale == 0 || math.IsInf(scale, 0) || math.IsNaN(scale) || math.IsInf(shift, 0) || math.IsNaN(shift) {
+		return nil, errors.New("scale must be non-zero, and both shift and scale finite")
+	}
+	affine := affineDist{dist, shift, scale}
+	return attachCDF(dist, affine, func(c CDFer, y float64) float64 {
+		if scale < 0 {
+			return 1 - c.CDF((y-shift)/scale)
+		}
+		return c.CDF((y - shift) / scale)
+	}), nil
+}
+
+func (d affineDist) Transform(x float64) float64 {
+	if d.scale < 0 {
+		x = reflect(x)
+	}
+	return d.shift + d.scale*d.dist.Transform(x)
+}
+
+func (d affineDist) Prob(y float64) float64 {
+	return d.dist.Prob((y-d.shift)/d.scale) / math.Abs(d.scale)
+}
+
+func (d affineDist) Support() (float64, float64) {
+	min, max := d.dist.Support()
+	min, max = d.shift+d.scale*min, d.shift+d.scale*max
+	if d.scale < 0 {
+		min, max = max, min
+	}
+	return min, max
+}
+
+type truncatedDist struct {
+	dist         Distribution
+	cdf          CDFer
+	a, b, fa, fb float64
+}
+
+// NewTruncated returns dist truncated to [a, b]. The
+// bounds may be infinite, and dist must implement CDFer.
+// Transform uses the inverse CDF of dist, i.e. its
+// Transform, restricted to [F(a), F(b)]. This loses
+// accuracy if [a, b] lies far in the tails of dist
+// (for the normal distribution use TruncatedNormalDist).
+// The result implements CDFer.
+func NewTruncated(dist Distribution, a, b float64) (Distribution, error) {
+	if dist == nil {
+		return nil, errors.New("need to provide a distribution")
+	}
+	cdf, ok := dist.(CDFer)
+	if !ok {
+		return nil, errors.New("the distribution needs to implement CDFer")
+	}
+	min, max := dist.Support()
+	a, b = math.Max(a, min), math.Min(b, max)
+	if !(a < b) {
+		return nil, errors.New("invalid range")
+	}
+	d := truncatedDist{dist, cdf, a, b, cdf.CDF(a), cdf.CDF(b)}
+	if !(d.fb > d.fa) {
+		return nil, errors.New("the distribution has no mass within the range")
+	}
+	return d, nil
+}
+
+func (d truncatedDist) Transform(x float64) float64 {
+	y := d.dist.Transform(d.fa + x*(d.fb-d.fa))
+	// Rounding can leave the support
+	return math.Max(d.a, math.Min(d.b, y))
+}
+
+func (d truncatedDist) Prob(x float64) float64 {
+	if x < d.a || x > d.b {
+		return 0
+	}
+	return d.dist.Prob(x) / (d.fb - d.fa)
+}
+
+func (d truncatedDist) Support() (float64, float64) {
+	return d.a, d.b
+}
+
+func (d truncatedDist) CDF(x float64) float64 {
+	x = math.Max(d.a, math.Min(d.b, x))
+	return math.Max(0, math.Min(1, (d.cdf.CDF(x)-d.fa)/(d.fb-d.fa)))
+}
+
+type mappedDist struct {
+	dist          Distribution
+	fn, inv, dinv func(float64) float64
+	min, max      float64
+	decreasing    bool
+}
+
+// NewMapped returns the distribution of fn(x), where
+// x is distributed according to dist, and fn is strictly
+// monotone on the support of dist. The inverse of fn
+// needs to be provided, as well as the derivative of
+// the inverse, which is the Jacobian
+//
+//     p_y(y) = p_x(inv(y)) |dinv(y)|
+//
+// If dinv is nil, it is computed numerically. The
+// result implements CDFer if dist does.
+func NewMapped(dist Distribution, fn, inv, dinv func(float64) float64) (Distribution, error) {
+	if dist == nil || fn == nil || inv == nil {
+		return nil, errors.New("need to provide a distribution, function and inverse")
+	}
+	if dinv == nil {
+		dinv = func(y float64) float64 {
+			return fd.Derivative(inv, y, nil)
+		}
+	}
+	a, b := dist.Support()
+	d := mappedDist{dist: dist, fn: fn, inv: inv, dinv: dinv, min: fn(a), max: fn(b)}
+	switch {
+	case d.min < d.max:
+	case d.min > d.max:
+		d.min, d.max = d.max, d.min
+		d.decreasing = true
+	default:
+		return nil, errors.New("the function must be strictly monotone")
+	}
+	decreasing := d.decreasing
+	return attachCDF(dist, d, func(c CDFer, y float64) float64 {
+		if decreasing {
+			return 1 - c.CDF(inv(y))
+		}
+		return c.CDF(inv(y))
+	}), nil
+}
+
+func (d mappedDist) Transform(x float64) float64 {
+	if d.decreasing {
+		x = reflect(x)
+	}
+	return math.Max(d.min, math.Min(d.max, d.fn(d.dist.Transform(x))))
+}
+
+func (d mappedDist) Prob(y float64) float64 {
+	if y < d.min || y > d.max {
+		return 0
+	}
+	return d.dist.Prob(d.inv(y)) * math.Abs(d.dinv(y))
+}
+
+func (d mappedDist) Support() (float64, float64) {
+	return d.min, d.max
+}
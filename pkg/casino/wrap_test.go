@@ -0,0 +1,93 @@
+package casino
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestWrappers(t *testing.T) {
+	affine, err := NewAffine(GammaDist{2, 1}, 1, -0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	truncated, err := NewTruncated(NormalDist{1, 1}, 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	halfCauchy, err := NewTruncated(CauchyDist{0, 1}, 0, math.Inf(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logNormal, err := NewMapped(NormalDist{0, 0.5}, math.Exp, math.Log, func(y float64) float64 { return 1 / y })
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Decreasing with numerical Jacobian
+	inverse, err := NewMapped(UniDistAB{1, 2}, func(x float64) float64 { return 1 / x }, func(y float64) float64 { return 1 / y }, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Wrappers of wrappers
+	nested, err := NewTruncated(affine, -1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dists := []Distribution{affine, truncated, halfCauchy, logNormal, inverse, nested}
+	supports := []float64{
+		math.Inf(-1), 1,
+		0, 2,
+		0, math.Inf(1),
+		0, math.Inf(1),
+		0.5, 1,
+		-1, 0,
+	}
+	ranges := []float64{
+		-4.1, 1.1,
+		-0.1, 2.1,
+		-0.1, 5.1,
+		0.05, 3.05,
+		0.45, 1.05,
+		-1.05, 0.05,
+	}
+	for i, dist := range dists {
+		if min, max := dist.Support(); min != supports[2*i] || max != supports[2*i+1] {
+			t.Error(fmt.Sprintf("%v: support [%v, %v] should be [%v, %v]", i, min, max, supports[2*i], supports[2*i+1]))
+		}
+		helperTestHistogram(dist, ranges[2*i], ranges[2*i+1], t)
+
+		// Transform is the inverse CDF
+		cdf, ok := dist.(CDFer)
+		if !ok {
+			t.Error(fmt.Sprintf("%v: does not implement CDFer", i))
+			continue
+		}
+		for _, u := range []float64{0.1, 0.5, 0.9} {
+			if v := cdf.CDF(dist.Transform(u)); math.Abs(v-u) > 1e-6 {
+				t.Error(fmt.Sprintf("%v: CDF(Transform(%v)) = %v", i, u, v))
+			}
+		}
+	}
+
+	// The mapped density matches the known one
+	for _, x := range []float64{0.1, 1, 3} {
+		if p, q := logNormal.Prob(x), (LogNormalDist{0, 0.5}).Prob(x); math.Abs(p-q) > 1e-12 {
+			t.Error(fmt.Sprintf("log-normal density at %v is %v, should be %v", x, p, q))
+		}
+	}
+
+	if _, err := NewAffine(NormalDist{0, 1}, 0, 0); err == nil {
+		t.Error("should reject zero scale")
+	}
+	if _, err := NewTruncated(NormalDist{0, 1}, 1, -1); err == nil {
+		t.Error("should reject invalid range")
+	}
+	mixture, _ := NewMixture([]float64{1, 1}, NormalDist{0, 1}, NormalDist{1, 1})
+	if _, err := NewTruncated(mixture, 0, 1); err == nil {
+		t.Error("should reject distribution without CDF")
+	}
+	if _, err := NewMapped(NormalDist{0, 1}, math.Cos, math.Acos, nil); err == nil {
+		t.Error("should reject non-monotone function")
+	}
+}
@@ -0,0 +1,112 @@
+package interpolate
+
+import "math"
+
+// MonotoneRange is a piecewise cubic Hermite interpolation,
+// which preserves the monotonicity of the data.
+type MonotoneRange struct {
+	xs []float64
+	ys []float64
+	ms []float64
+}
+
+// NewMonotoneRange creates a monotone cubic range, using the
+// derivative estimates given in:
+//
+//     Fritsch, F. N.; Butland, J. (1984). "A Method for Constructing Local
+//     Monotone Piecewise Cubic Interpolants". SIAM J. Sci. Stat. Comput.
+//     5 (2): 300–304.
+//
+// If ys is monotone, so is the interpolation. The xs must
+// be strictly increasing. The data passed is not copied, but
+// referenced directly.
+func NewMonotoneRange(xs, ys []float64) (*MonotoneRange, error) {
+	if len(ys) < 2 || len(ys) != len(xs) {
+		return nil, ErrorDimMissmatch
+	}
+	for i := 1; i < len(xs); i++ {
+		if xs[i] <= xs[i-1] {
+			return nil, ErrorBadSpline
+		}
+	}
+
+	n := len(xs)
+	ms := make([]float64, n, n)
+	// Secant slopes
+	ds := make([]float64, n-1, n-1)
+	for i := range ds {
+		ds[i] = (ys[i+1] - ys[i]) / (xs[i+1] - xs[i])
+	}
+	ms[0], ms[n-1] = ds[0], ds[n-2]
+	if n > 2 {
+		ms[0] = endSlope(xs[1]-xs[0], xs[2]-xs[1], ds[0], ds[1])
+		ms[n-1] = endSlope(xs[n-1]-xs[n-2], xs[n-2]-xs[n-3], ds[n-2], ds[n-3])
+	}
+	for i := 1; i < n-1; i++ {
+		if ds[i-1]*ds[i] <= 0 {
+			// Local extremum, keep the curve flat
+			ms[i] = 0
+			continue
+		}
+		h0, h1 := xs[i]-xs[i-1], xs[i+1]-xs[i]
+		ms[i] = 3 * (h0 + h1) / ((2*h1+h0)/ds[i-1] + (h1+2*h0)/ds[i])
+	}
+	return &MonotoneRange{xs, ys, ms}, nil
+}
+
+// Three point estimate of the slope at an end point,
+// limited to preserve monotonicity.
+func endSlope(h0, h1, d0, d1 float64) float64 {
+	m := ((2*h0+h1)*d0 - h0*d1) / (h0 + h1)
+	if m*d0 <= 0 {
+		return 0
+	} else if d0*d1 <= 0 && math.Abs(m) > math.Abs(3*d0) {
+		return 3 * d0
+	}
+	return m
+}
+
+// NewMonotoneRangeCopy is like NewMonotoneRange, except the passed
+// data is copied.
+func NewMonotoneRangeCopy(xs, ys []float64) (*MonotoneRange, error) {
+	xsCopy := make([]float64, len(xs), len(xs))
+	ysCopy := make([]float64, len(ys), len(ys))
+	copy(xsCopy, xs)
+	copy(ysCopy, ys)
+	return NewMonotoneRange(xsCopy, ysCopy)
+}
+
+// Bounds implements a Range.
+func (r *MonotoneRange) Bounds() (float64, float64) {
+	return r.xs[0], r.xs[len(r.xs)-1]
+}
+
+// InBounds implements a Range.
+func (r *MonotoneRange) InBounds(x float64) bool {
+	min, max := r.Bounds()
+	return min <= x && max >= x
+}
+
+// Eval implements a Range.
+func (r *MonotoneRange) Eval(x float64) (y float64, err error) {
+	if !r.InBounds(x) {
+		return 0, ErrorOutOfBounds
+	}
+	// Perform binary search to find points for x
+	bot, top := 0, len(r.xs)-1
+	mid := (bot + top) / 2
+	for bot+1 < top {
+		if r.xs[mid] > x {
+			top = mid
+		} else {
+			bot = mid
+		}
+		mid = (bot + top) / 2
+	}
+	// Evaluate cubic Hermite basis
+	h := r.xs[top] - r.xs[bot]
+	t := (x - r.xs[bot]) / h
+	t2, t3 := t*t, t*t*t
+	return r.ys[bot] + (3*t2-2*t3)*(r.ys[top]-r.ys[bot]) +
+		h*((t3-2*t2+t)*r.ms[bot]+(t3-t2)*r.ms[top]), nil
+}
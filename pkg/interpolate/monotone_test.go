@@ -0,0 +1,56 @@
+package interpolate
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+// Test monotone interpolation
+func TestMonotone(t *testing.T) {
+	// Step-like data, where a spline would overshoot
+	xs := []float64{0, 1, 2, 3, 4, 5, 6}
+	ys := []float64{0, 0, 0.1, 5, 5.1, 5.1, 6}
+
+	r, err := NewMonotoneRange(xs, ys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev := math.Inf(-1)
+	for i := 0; i <= 600; i++ {
+		x := float64(i) / 100
+		y, err := r.Eval(x)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if y < prev {
+			t.Error(fmt.Sprintf("interpolation not monotone at %v (%v < %v)", x, y, prev))
+		}
+		prev = y
+	}
+
+	// Data points are reproduced exactly
+	for i := range xs {
+		if y, _ := r.Eval(xs[i]); !approx(y, ys[i]) {
+			t.Error(fmt.Sprintf("%v is not approximately %v", y, ys[i]))
+		}
+	}
+
+	// Smooth functions are approximated well
+	xs, ys = make([]float64, 100), make([]float64, 100)
+	for i := range xs {
+		xs[i] = float64(i) / 99
+		ys[i] = math.Sin(xs[i])
+	}
+	r, _ = NewMonotoneRange(xs, ys)
+	for i := 0; i < 1000; i++ {
+		x := float64(i) / 999
+		if y, _ := r.Eval(x); math.Abs(y-math.Sin(x)) > 1e-6 {
+			t.Error(fmt.Sprintf("%v is not approximately %v", y, math.Sin(x)))
+		}
+	}
+
+	if _, err := NewMonotoneRange([]float64{0, 0}, []float64{1, 2}); err != ErrorBadSpline {
+		t.Error("should reject non-increasing xs")
+	}
+}
@@ -6,6 +6,8 @@ const (
 	ErrorConverge Error = iota
 	ErrorMinSteps
 	ErrorInsufficientSteps
+	ErrorSharedScheme
+	ErrorBudget
 )
 
 func (err Error) Error() string {
@@ -16,6 +18,10 @@ func (err Error) Error() string {
 		return "the number of steps allowed is lower than the min needed"
 	case ErrorInsufficientSteps:
 		return "not enough steps taken to determine convergence"
+	case ErrorSharedScheme:
+		return "every level of an iterated integral needs its own scheme"
+	case ErrorBudget:
+		return "evaluation budget of the iterated integral exhausted"
 	default:
 		return "unknown error"
 	}
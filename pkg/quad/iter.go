@@ -0,0 +1,232 @@
+package quad
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+)
+
+// Integrate2D evaluates the iterated integral
+//
+//     int_a^b int_g(x)^h(x) fn(x, y) dy dx
+//
+// using outer for the x-integral and inner for
+// every y-integral. Nil schemes default to
+// Simpson's rule, as for Integrate.
+//
+// The accuracy set on outer is the target for
+// the full integral. Half of it is allotted to
+// the outer integral, the other half is spread
+// over the inner integrals, which are asked to
+// be accurate to acc / (2 (b - a)). The accuracies
+// of both schemes are restored before returning.
+//
+// The steps set on outer are the budget for the
+// evaluations of fn across all levels. Once it is
+// exhausted, fn is no longer called and ErrorBudget
+// is returned. A negative budget means no limit.
+//
+// The returned Stats aggregate all levels: Steps
+// counts every evaluation of fn, Accuracy bounds
+// the combined error and Error reports the first
+// error encountered (an exhausted budget and outer
+// errors take precedence).
+//
+// Calls to inner are serialized, so the outer scheme
+// may use several workers. The bounds a, b must be
+// finite. Every level needs its own scheme, passing
+// the same one for outer and inner results in
+// ErrorSharedScheme.
+func Integrate2D(fn func(x, y float64) float64, a, b float64, g, h func(float64) float64, outer, inner Integral) (float64, *Stats, error) {
+	if outer != nil && outer == inner {
+		return 0, &Stats{Error: ErrorSharedScheme}, ErrorSharedScheme
+	}
+	if outer == nil {
+		outer = NewSimpsonIntegral(1)
+	}
+	if inner == nil {
+		inner = NewSimpsonIntegral(1)
+	}
+
+	innerAcc := inner.Accuracy(nil)
+	defer inner.Accuracy(&innerAcc)
+
+	budget := budget{limit: int64(outer.Steps(nil))}
+	lock := sync.Mutex{}
+	return budget.finish(iterate(a, b, outer.Accuracy(nil), outer, func(x, acc float64) (float64, *Stats) {
+		if budget.exhausted() {
+			return 0, &Stats{}
+		}
+		lock.Lock()
+		defer lock.Unlock()
+		inner.Accuracy(&acc)
+		val, _ := Integrate(func(y float64) float64 {
+			if !budget.take() {
+				return 0
+			}
+			return fn(x, y)
+		}, g(x), h(x), inner)
+		return val, copyStats(inner.Stats())
+	}))
+}
+
+// Integrate3D evaluates the iterated integral
+//
+//     int_a^b int_g(x)^h(x) int_q(x,y)^r(x,y) fn(x, y, z) dz dy dx
+//
+// using outer, middle and inner for the x, y and z
+// integrals respectively. The accuracy set on outer
+// is shared between the levels as described for
+// Integrate2D, as is the budget of evaluations set
+// by the steps of outer. The returned Stats are
+// aggregated in the same way. The three schemes
+// must differ.
+func Integrate3D(fn func(x, y, z float64) float64, a, b float64, g, h func(float64) float64, q, r func(x, y float64) float64, outer, middle, inner Integral) (float64, *Stats, error) {
+	if (outer != nil && (outer == middle || outer == inner)) || (middle != nil && middle == inner) {
+		return 0, &Stats{Error: ErrorSharedScheme}, ErrorSharedScheme
+	}
+	if outer == nil {
+		outer = NewSimpsonIntegral(1)
+	}
+	if middle == nil {
+		middle = NewSimpsonIntegral(1)
+	}
+	if inner == nil {
+		inner = NewSimpsonIntegral(1)
+	}
+
+	middleAcc := middle.Accuracy(nil)
+	defer middle.Accuracy(&middleAcc)
+	innerAcc := inner.Accuracy(nil)
+	defer inner.Accuracy(&innerAcc)
+
+	budget := budget{limit: int64(outer.Steps(nil))}
+	middleLock, innerLock := sync.Mutex{}, sync.Mutex{}
+	return budget.finish(iterate(a, b, outer.Accuracy(nil), outer, func(x, acc float64) (float64, *Stats) {
+		if budget.exhausted() {
+			return 0, &Stats{}
+		}
+		middleLock.Lock()
+		defer middleLock.Unlock()
+		val, stats, _ := iterate(g(x), h(x), acc, middle, func(y, acc float64) (float64, *Stats) {
+			if budget.exhausted() {
+				return 0, &Stats{}
+			}
+			innerLock.Lock()
+			defer innerLock.Unlock()
+			inner.Accuracy(&acc)
+			val, _ := Integrate(func(z float64) float64 {
+				if !budget.take() {
+					return 0
+				}
+				return fn(x, y, z)
+			}, q(x, y), r(x, y), inner)
+			return val, copyStats(inner.Stats())
+		})
+		return val, stats
+	}))
+}
+
+// Counts the evaluations of the integrand of an
+// iterated integral against a budget shared by all
+// levels. A negative limit means no limit.
+type budget struct {
+	limit, used int64
+	refused     int32
+}
+
+// Take a single evaluation, returns false once
+// the budget is exhausted
+func (b *budget) take() bool {
+	if atomic.AddInt64(&b.used, 1) <= b.limit || b.limit < 0 {
+		return true
+	}
+	atomic.StoreInt32(&b.refused, 1)
+	return false
+}
+
+// Returns true if no evaluation is left, which
+// means the caller's evaluation is refused
+func (b *budget) exhausted() bool {
+	if b.limit >= 0 && atomic.LoadInt64(&b.used) >= b.limit {
+		atomic.StoreInt32(&b.refused, 1)
+		return true
+	}
+	return false
+}
+
+// Report the evaluations made and replace the
+// error by ErrorBudget, if any were refused
+func (b *budget) finish(val float64, stats *Stats, err error) (float64, *Stats, error) {
+	stats.Steps = int(atomic.LoadInt64(&b.used))
+	if b.limit >= 0 && int64(stats.Steps) > b.limit {
+		stats.Steps = int(b.limit)
+	}
+	if atomic.LoadInt32(&b.refused) != 0 {
+		stats.Error = ErrorBudget
+		return val, stats, ErrorBudget
+	}
+	return val, stats, err
+}
+
+// Integrates the function returned by
+// level over [a, b] to the given accuracy.
+// level is passed the accuracy it should
+// achieve and reports the stats of its
+// own evaluation.
+func iterate(a, b, acc float64, scheme Integral, level func(x, acc float64) (float64, *Stats)) (float64, *Stats, error) {
+	// Split accuracy evenly between this and the next level
+	prevAcc := scheme.Accuracy(nil)
+	defer scheme.Accuracy(&prevAcc)
+	outerAcc := acc / 2
+	scheme.Accuracy(&outerAcc)
+
+	levelAcc := acc / 2
+	if width := math.Abs(b - a); width > 0 && !math.IsInf(width, 0) {
+		levelAcc /= width
+	}
+
+	stats := &Stats{}
+	var levelErr error
+	var maxAcc float64
+	lock := sync.Mutex{}
+
+	val, err := Integrate(func(x float64) float64 {
+		y, s := level(x, levelAcc)
+		lock.Lock()
+		defer lock.Unlock()
+		stats.Steps += s.Steps
+		maxAcc = math.Max(maxAcc, s.Accuracy)
+		if levelErr == nil {
+			levelErr = s.Error
+		}
+		return y
+	}, a, b, scheme)
+
+	if s := scheme.Stats(); s != nil {
+		stats.Accuracy = s.Accuracy
+	}
+	width := math.Abs(b - a)
+	if math.IsInf(width, 0) {
+		width = 1
+	}
+	stats.Accuracy += width * maxAcc
+
+	if err != nil {
+		stats.Error = err
+	} else {
+		stats.Error = levelErr
+	}
+	return val, stats, stats.Error
+}
+
+// Copy stats, so they can be used after the
+// scheme has been run again. A nil stats pointer
+// results in empty stats.
+func copyStats(s *Stats) *Stats {
+	if s == nil {
+		return &Stats{}
+	}
+	c := *s
+	return &c
+}
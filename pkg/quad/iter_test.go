@@ -0,0 +1,129 @@
+package quad
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"testing"
+)
+
+func TestIntegrate2D(t *testing.T) {
+	called := 0
+	mut := sync.Mutex{}
+	fn := func(x, y float64) float64 {
+		mut.Lock()
+		called++
+		mut.Unlock()
+		return x * y
+	}
+
+	// Triangle 0 <= y <= x <= 1
+	val, stats, err := Integrate2D(fn, 0, 1,
+		func(_ float64) float64 { return 0 },
+		func(x float64) float64 { return x },
+		NewSimpsonIntegral(4), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(val-1.0/8.0) > defaultAccuracy {
+		t.Error(fmt.Sprintf("result %v is not approximately %v (stats: %v)", val, 1.0/8.0, stats))
+	}
+	if called != stats.Steps {
+		t.Error(fmt.Sprintf("was called %v, reported to have been called %v", called, stats.Steps))
+	}
+	if stats.Accuracy > defaultAccuracy {
+		t.Error(fmt.Sprintf("accuracy %v exceeds requested %v", stats.Accuracy, defaultAccuracy))
+	}
+}
+
+func TestIntegrate3D(t *testing.T) {
+	acc, budget := 1e-4, 4000000
+	outer := NewSimpsonIntegral(4)
+	outer.Accuracy(&acc)
+	outer.Steps(&budget)
+	inner := NewTrapezoidalIntegral(1)
+
+	// Tetrahedron x, y, z >= 0, x + y + z <= 1
+	val, stats, err := Integrate3D(func(x, y, z float64) float64 { return x }, 0, 1,
+		func(_ float64) float64 { return 0 },
+		func(x float64) float64 { return 1 - x },
+		func(_, _ float64) float64 { return 0 },
+		func(x, y float64) float64 { return 1 - x - y },
+		outer, nil, inner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(val-1.0/24.0) > acc {
+		t.Error(fmt.Sprintf("result %v is not approximately %v (stats: %v)", val, 1.0/24.0, stats))
+	}
+	if stats.Steps > budget {
+		t.Error(fmt.Sprintf("made %v evaluations, budget is %v", stats.Steps, budget))
+	}
+
+	// Accuracies should be restored
+	if outer.Accuracy(nil) != acc || inner.Accuracy(nil) != defaultAccuracy {
+		t.Error("scheme accuracies were not restored")
+	}
+}
+
+// Sharing a scheme between levels would deadlock,
+// since the inner level changes the accuracy of
+// the scheme while the outer level integrates.
+func TestIntegrateSharedScheme(t *testing.T) {
+	fn2 := func(x, y float64) float64 { return x * y }
+	fn3 := func(x, y, z float64) float64 { return x * y * z }
+	zero, one := func(_ float64) float64 { return 0 }, func(_ float64) float64 { return 1 }
+	zero2, one2 := func(_, _ float64) float64 { return 0 }, func(_, _ float64) float64 { return 1 }
+
+	s := NewSimpsonIntegral(2)
+	if _, _, err := Integrate2D(fn2, 0, 1, zero, one, s, s); err != ErrorSharedScheme {
+		t.Error(fmt.Sprintf("expected %v, got %v", ErrorSharedScheme, err))
+	}
+	for i, schemes := range [][3]Integral{{s, s, nil}, {s, nil, s}, {nil, s, s}} {
+		if _, _, err := Integrate3D(fn3, 0, 1, zero, one, zero2, one2, schemes[0], schemes[1], schemes[2]); err != ErrorSharedScheme {
+			t.Error(fmt.Sprintf("case %v: expected %v, got %v", i, ErrorSharedScheme, err))
+		}
+	}
+	if val, _, err := Integrate2D(fn2, 0, 1, zero, one, nil, nil); err != nil || math.Abs(val-0.25) > defaultAccuracy {
+		t.Error(fmt.Sprintf("default schemes should be distinct: %v, %v", val, err))
+	}
+}
+
+// The steps of outer bound the evaluations
+// of fn across all levels.
+func TestIntegrateBudget(t *testing.T) {
+	var called int64
+	mut := sync.Mutex{}
+	fn := func(x, y, z float64) float64 {
+		mut.Lock()
+		called++
+		mut.Unlock()
+		return math.Sin(10*x*y) * z
+	}
+	zero, one := func(_ float64) float64 { return 0 }, func(_ float64) float64 { return 1 }
+	zero2, one2 := func(_, _ float64) float64 { return 0 }, func(_, _ float64) float64 { return 1 }
+
+	acc, steps := 1e-10, 5000
+	outer := NewSimpsonIntegral(2)
+	outer.Accuracy(&acc)
+	outer.Steps(&steps)
+	_, stats, err := Integrate3D(fn, 0, 1, zero, one, zero2, one2, outer, nil, nil)
+	if err != ErrorBudget || stats.Error != ErrorBudget {
+		t.Error(fmt.Sprintf("expected %v, got %v", ErrorBudget, err))
+	}
+	if called > int64(steps) || stats.Steps != int(called) {
+		t.Error(fmt.Sprintf("made %v evaluations (reported %v), budget is %v", called, stats.Steps, steps))
+	}
+
+	// Sufficient budget
+	called = 0
+	steps = -1
+	outer.Steps(&steps)
+	val, stats, err := Integrate2D(func(x, y float64) float64 { return fn(x, y, 1) }, 0, 1, zero, one, outer, nil)
+	if err != nil || stats.Steps != int(called) {
+		t.Error(fmt.Sprintf("unlimited budget gives %v after %v (reported %v) evaluations", err, called, stats.Steps))
+	}
+	if want := 0.2925257; math.Abs(val-want) > 1e-6 {
+		t.Error(fmt.Sprintf("result %v is not approximately %v", val, want))
+	}
+}
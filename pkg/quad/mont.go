@@ -23,17 +23,34 @@ type monteCaroloIntegral struct {
 	function       func(float64) float64
 	workers, batch int
 	seeds          []uint64
+	options        MonteCarloOptions
 	stats          *Stats
 
 	lock sync.RWMutex
 }
 
-// Pad seed array
-func padSeeds(seeds []uint64, n int) []uint64 {
-	for i := 0; len(seeds) < n; i++ {
-		seeds = append(seeds, seeds[i]+1)
+// MonteCarloOptions configures variance
+// reduction for Monte-Carlo integrals,
+// see casino.Expectation.
+type MonteCarloOptions struct {
+	// Evaluate the integrand at antithetic
+	// pairs. Every pair counts as two steps.
+	Antithetic bool
+	// Functions with known Integrals over
+	// the integration bounds, which are used
+	// as control variates.
+	Controls  []func(float64) float64
+	Integrals []float64
+}
+
+// Derive a seed source from the given seeds,
+// which supplies workers without a seed.
+func seedSource(seeds []uint64) *casino.SeedSource {
+	src := casino.NewSeedSource(uint64(len(seeds)))
+	for _, seed := range seeds {
+		src = casino.NewSeedSource(src.Seed() ^ seed)
 	}
-	return seeds
+	return src
 }
 
 // Returns an integral that is evaluated using a
@@ -50,21 +67,29 @@ func padSeeds(seeds []uint64, n int) []uint64 {
 // This means, we always run workers*batch
 // steps at a time to refine.
 //
-// You should always provide as many seeds as
-// workers. If insufficient seeds are provided,
-// the seed slice is padded with extra seeds
-// by incrementing the previous seeds. Note
-// that this strategy may lead to duplicate
-// seeds.
-func NewMonteCarloIntegral(dist casino.Distribution, workers, batch int, seeds []uint64) Integral {
-	return &monteCaroloIntegral{
+// Every worker uses its own random stream.
+// If fewer seeds than workers are provided,
+// the remaining workers draw their seeds
+// from a casino.SeedSource derived from the
+// given seeds, so the results remain
+// reproducible.
+//
+// Variance reduction can be enabled by
+// passing MonteCarloOptions, which may be
+// nil for plain sampling.
+func NewMonteCarloIntegral(dist casino.Distribution, workers, batch int, seeds []uint64, options *MonteCarloOptions) Integral {
+	mont := &monteCaroloIntegral{
 		Distribution: dist,
 		accuracy:     defaultMonteCarloAccuracy,
 		steps:        defaultMonteCarloStep,
 		workers:      workers,
 		batch:        batch,
-		seeds:        padSeeds(seeds, workers),
+		seeds:        seeds,
 	}
+	if options != nil {
+		mont.options = *options
+	}
+	return mont
 }
 
 // Accuracy implements Integral
@@ -120,17 +145,37 @@ func (mont *monteCaroloIntegral) Integrate(a, b float64) (float64, error) {
 		Function: func(x float64) float64 {
 			return mont.function(x) / mont.Prob(x)
 		},
-		Seeds: mont.seeds,
+		Seeds:      mont.seeds,
+		Source:     seedSource(mont.seeds),
+		Antithetic: mont.options.Antithetic,
+	}
+	// Controls are importance sampled
+	// like the integrand
+	for i := range mont.options.Controls {
+		control := mont.options.Controls[i]
+		exp.Controls = append(exp.Controls, func(x float64) float64 {
+			return control(x) / mont.Prob(x)
+		})
+	}
+	exp.ControlMeans = mont.options.Integrals
+	if len(exp.Controls) != len(exp.ControlMeans) {
+		return 0, errors.New("need to provide the same amount of controls and integrals")
+	}
+
+	// Antithetic pairs take two steps
+	trials, cost := mont.batch, 1
+	if mont.options.Antithetic {
+		trials, cost = (mont.batch+1)/2, 2
 	}
 
 	steps := 0
-	for steps+mont.batch*mont.workers < mont.steps {
-		steps += mont.batch * mont.workers
-		res := exp.Refine(mont.batch, mont.workers)
+	for steps+cost*trials*mont.workers < mont.steps {
+		steps += cost * trials * mont.workers
+		res := exp.Refine(trials, mont.workers)
 		// sigma on expectation estimate is ~ sqrt(variance_estimate / n)
 		// (from central limit theorem)
 		// -> we want to be within 2 sigma
-		if mont.accuracy >= 2*math.Sqrt(res.Variance/float64(steps)) {
+		if mont.accuracy >= 2*res.StdErr {
 			// We are happy with the results
 			break
 		}
@@ -138,7 +183,15 @@ func (mont *monteCaroloIntegral) Integrate(a, b float64) (float64, error) {
 
 	// Return final result
 	res := exp.Result()
-	mont.stats = &Stats{steps, 2 * math.Sqrt(res.Variance/float64(steps)), nil}
+	mont.stats = &Stats{
+		Steps:    steps,
+		Accuracy: 2 * res.StdErr,
+		Time:     res.Time,
+		BusyTime: res.BusyTime,
+	}
+	if res.Time > 0 {
+		mont.stats.Rate = float64(steps) / res.Time.Seconds()
+	}
 
 	// If we couldn't take any steps, then we have no
 	// estimate for anything ...
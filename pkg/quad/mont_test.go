@@ -1,23 +1,76 @@
 package quad
 
 import (
+	"fmt"
+	"math"
 	"testing"
 
 	"github.com/dyedgreen/comp-phys/pkg/casino"
 )
 
-func TestMont(t *testing.T) {
-	// We use a uniform integral, as this
-	// allows us to easily test different ranges
-	scheme := NewUniformMonteCarloIntegral(1000, 64, casino.Noise(64))
-	acc := 0.5 // Monte Carlo takes a while to converge
-	scheme.Accuracy(&acc)
-	helperTestResults(scheme, t)
-}
-
 // Ensure step limit and statistic function as
 // advertised.
 func TestMontLimit(t *testing.T) {
-	scheme := NewUniformMonteCarloIntegral(1000, 64, casino.Noise(64))
+	scheme := NewUniformMonteCarloIntegral(1000, 64, casino.Noise(64), nil)
 	helperTestLimits(scheme, 0, t)
 }
+
+// Variance reduction reaches the accuracy
+// with fewer steps.
+func TestMontReduction(t *testing.T) {
+	options := []MonteCarloOptions{
+		{},
+		{Antithetic: true},
+		{Controls: []func(float64) float64{func(x float64) float64 { return 1 + x }}, Integrals: []float64{1.5}},
+		{Antithetic: true, Controls: []func(float64) float64{func(x float64) float64 { return x * x }}, Integrals: []float64{1.0 / 3.0}},
+	}
+	var steps []int
+	for _, opt := range options {
+		scheme := NewUniformMonteCarloIntegral(16, 64, casino.Noise(16), &opt)
+		acc := 1e-3
+		scheme.Accuracy(&acc)
+		val, err := Integrate(math.Exp, 0, 1, scheme)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(val-(math.E-1)) > 5e-3 {
+			t.Error(fmt.Sprintf("%v is not approximately %v", val, math.E-1))
+		}
+		if stats := scheme.Stats(); stats.Time <= 0 || len(stats.BusyTime) != 16 || stats.Rate <= 0 {
+			t.Error(fmt.Sprintf("missing timing: %v", stats))
+		}
+		steps = append(steps, scheme.Stats().Steps)
+	}
+	for i := 1; i < len(steps); i++ {
+		if 10*steps[i] > steps[0] {
+			t.Error(fmt.Sprintf("options %v took %v steps, plain sampling took %v", i, steps[i], steps[0]))
+		}
+	}
+
+	scheme := NewUniformMonteCarloIntegral(16, 64, casino.Noise(16), &MonteCarloOptions{Controls: options[2].Controls})
+	if _, err := Integrate(math.Exp, 0, 1, scheme); err == nil {
+		t.Error("should reject missing integrals")
+	}
+}
+
+// Mixtures can be used as importance densities.
+func TestMontMixture(t *testing.T) {
+	linear, err := casino.NewLinearDist(0, 1, 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dist, err := casino.NewMixture([]float64{1, 1}, casino.UniDistAB{A: 0, B: 1}, linear)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scheme := NewMonteCarloIntegral(dist, 16, 64, casino.Noise(16), nil)
+	acc := 1e-3
+	scheme.Accuracy(&acc)
+	val, err := Integrate(math.Exp, 0, 1, scheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(val-(math.E-1)) > 5e-3 {
+		t.Error(fmt.Sprintf("%v is not approximately %v", val, math.E-1))
+	}
+}
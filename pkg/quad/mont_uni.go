@@ -6,14 +6,18 @@ type uniformMonteCarloIntegral monteCaroloIntegral
 
 // NewUniformIntegral is a helper for creating a Monte-Carlo
 // integral with uniform sampling function.
-func NewUniformMonteCarloIntegral(workers, batch int, seeds []uint64) Integral {
-	return &uniformMonteCarloIntegral{
+func NewUniformMonteCarloIntegral(workers, batch int, seeds []uint64, options *MonteCarloOptions) Integral {
+	mont := &uniformMonteCarloIntegral{
 		accuracy: defaultMonteCarloAccuracy,
 		steps:    defaultMonteCarloStep,
 		workers:  workers,
 		batch:    batch,
-		seeds:    padSeeds(seeds, workers),
+		seeds:    seeds,
 	}
+	if options != nil {
+		mont.options = *options
+	}
+	return mont
 }
 
 // Accuracy implements Integral
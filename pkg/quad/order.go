@@ -0,0 +1,142 @@
+package quad
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// Order holds an empirical convergence
+// estimate of the form
+//
+//     |error| ~ Constant * N^-Order
+//
+// where N is the number of function
+// evaluations.
+type Order struct {
+	Order float64
+	// Confidence interval on Order
+	Low, High float64
+	Level     float64
+	// Asymptotic constant
+	Constant float64
+	// Number of points used in the fit
+	Points int
+}
+
+func (o Order) String() string {
+	return fmt.Sprintf("O(N^-%.3f) (%v%% CI [%.3f, %.3f]), C = %v, %v points",
+		o.Order, o.Level*100, o.Low, o.High, o.Constant, o.Points)
+}
+
+// Theory returns the fitted error at n
+// function evaluations.
+func (o Order) Theory(n float64) float64 {
+	return o.Constant * math.Pow(n, -o.Order)
+}
+
+// ConvergenceOrder integrates fn between a and b with
+// scheme once for every step budget in steps, and fits
+// the observed errors against the function evaluations
+// taken on a log-log scale. The exact value of the
+// integral must be provided.
+//
+// level sets the confidence level of the interval
+// reported on the order (e.g. 0.95). The interval
+// is derived from Student's t-distribution.
+//
+// While fitting, the accuracy of scheme is set to the
+// lowest possible value, so that every run takes its
+// full budget. The previous accuracy and step limit are
+// restored before returning. Runs which can not take
+// any steps, take the same number of steps as a previous
+// run or hit the exact value are skipped.
+func ConvergenceOrder(scheme Integral, fn func(float64) float64, a, b, exact float64, steps []int, level float64) (*Order, error) {
+	if scheme == nil {
+		scheme = NewSimpsonIntegral(1)
+	}
+	if level <= 0 || level >= 1 {
+		return nil, errors.New("confidence level must be in (0, 1)")
+	}
+
+	prevAcc, prevSteps := scheme.Accuracy(nil), scheme.Steps(nil)
+	defer scheme.Accuracy(&prevAcc)
+	defer scheme.Steps(&prevSteps)
+	acc := 0.0
+	scheme.Accuracy(&acc)
+
+	seen := make(map[int]bool)
+	ns, errs := make([]float64, 0, len(steps)), make([]float64, 0, len(steps))
+	for _, n := range steps {
+		scheme.Steps(&n)
+		val, err := Integrate(fn, a, b, scheme)
+		if err == ErrorMinSteps {
+			continue
+		}
+		taken := scheme.Stats().Steps
+		if seen[taken] {
+			continue
+		}
+		seen[taken] = true
+		ns = append(ns, float64(taken))
+		errs = append(errs, math.Abs(val-exact))
+	}
+
+	return FitOrder(ns, errs, level)
+}
+
+// FitOrder fits the observed errors errs against the
+// function evaluations n on a log-log scale, as done
+// by ConvergenceOrder. Points with zero error are
+// skipped.
+func FitOrder(n, errs []float64, level float64) (*Order, error) {
+	if len(n) != len(errs) {
+		return nil, errors.New("need the same number of evaluation counts and errors")
+	}
+	if level <= 0 || level >= 1 {
+		return nil, errors.New("confidence level must be in (0, 1)")
+	}
+
+	logN, logErr := make([]float64, 0, len(n)), make([]float64, 0, len(n))
+	for i := range n {
+		if n[i] <= 0 {
+			return nil, errors.New("evaluation counts must be positive")
+		}
+		if errs[i] == 0 {
+			continue
+		}
+		logN = append(logN, math.Log(n[i]))
+		logErr = append(logErr, math.Log(errs[i]))
+	}
+
+	if len(logN) < 3 {
+		return nil, errors.New("need at least three distinct runs to fit convergence order")
+	}
+
+	// Fit log|err| = alpha + beta log(N)
+	alpha, beta := stat.LinearRegression(logN, logErr, nil, false)
+
+	// Standard error on the slope
+	var ssRes, ssN float64
+	meanN := stat.Mean(logN, nil)
+	for i := range logN {
+		res := logErr[i] - alpha - beta*logN[i]
+		ssRes += res * res
+		ssN += (logN[i] - meanN) * (logN[i] - meanN)
+	}
+	dof := float64(len(logN) - 2)
+	se := math.Sqrt(ssRes / dof / ssN)
+	t := distuv.StudentsT{Mu: 0, Sigma: 1, Nu: dof}.Quantile(0.5 + level/2)
+
+	return &Order{
+		Order:    -beta,
+		Low:      -beta - t*se,
+		High:     -beta + t*se,
+		Level:    level,
+		Constant: math.Exp(alpha),
+		Points:   len(logN),
+	}, nil
+}
@@ -0,0 +1,68 @@
+package quad
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/dyedgreen/comp-phys/pkg/casino"
+)
+
+func TestConvergenceOrder(t *testing.T) {
+	fn := func(x float64) float64 { return math.Exp(-x * x) }
+	exact := math.SqrtPi / 2 * math.Erf(2)
+
+	steps := make([]int, 0)
+	for n := 8; n < 1<<10; n *= 2 {
+		steps = append(steps, n)
+	}
+
+	schemes := []Integral{
+		NewTrapezoidalIntegral(4),
+		NewSimpsonIntegral(4),
+	}
+	orders := []float64{2, 4}
+
+	for i := range schemes {
+		order, err := ConvergenceOrder(schemes[i], fn, 0, 2, exact, steps, 0.95)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(order.Order-orders[i]) > 0.25 {
+			t.Error(fmt.Sprintf("order %v is not approximately %v", order, orders[i]))
+		}
+		if order.Low > order.Order || order.High < order.Order {
+			t.Error(fmt.Sprintf("order not within confidence interval %v", order))
+		}
+		if schemes[i].Accuracy(nil) != defaultAccuracy || schemes[i].Steps(nil) != defaultMaxStep {
+			t.Error("scheme settings were not restored")
+		}
+	}
+
+	// Monte Carlo should converge as N^-1/2
+	mont := NewUniformMonteCarloIntegral(16, 64, casino.Noise(16), nil)
+	steps = make([]int, 0)
+	for n := 1 << 10; n < 1<<20; n *= 2 {
+		steps = append(steps, n)
+	}
+	order, err := ConvergenceOrder(mont, fn, 0, 2, exact, steps, 0.95)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order.Low > 0.5 || order.High < 0.5 {
+		t.Error(fmt.Sprintf("order %v is not consistent with 0.5", order))
+	}
+
+	// Not enough points
+	if _, err := ConvergenceOrder(nil, fn, 0, 2, exact, []int{100}, 0.95); err == nil {
+		t.Error("should error with insufficient runs")
+	}
+
+	// Evaluation counts must be positive
+	if _, err := FitOrder([]float64{0, 10, 100}, []float64{1, 0.1, 0.01}, 0.95); err == nil {
+		t.Error("should error with a zero evaluation count")
+	}
+	if _, err := FitOrder([]float64{-1, 10, 100}, []float64{1, 0.1, 0.01}, 0.95); err == nil {
+		t.Error("should error with a negative evaluation count")
+	}
+}
@@ -2,57 +2,13 @@ package quad
 
 import (
 	"fmt"
-	"math"
 	"math/rand"
 	"sync"
 	"testing"
 )
 
-type fnSlice []func(float64) float64
-
 // Unit test helpers
 
-func helperTestResults(scheme Integral, t *testing.T) {
-	cases := fnSlice{
-		// Contains function -> analytic integral
-		func(x float64) float64 { return x * x },
-		func(x float64) float64 { return x * x * x / 3 },
-		math.Exp,
-		math.Exp,
-		func(x float64) float64 { return 4*x*x - 2*x + 4 },
-		func(x float64) float64 { return 4*x*x*x/3 - x*x + 4*x },
-	}
-
-	borders := []float64{
-		// Contains a, b pairs sequentially
-		0, 5,
-		-10, 4,
-		-4, 3.5,
-		3, 3.1,
-	}
-
-	// Generate more random test cases
-	randBorders := make([]float64, 20)
-	rand.Seed(42)
-	for i := 0; i < len(randBorders); i += 2 {
-		randBorders[i] = rand.Float64()*15 - 10
-		randBorders[i+1] = randBorders[i] + rand.Float64()*(5-randBorders[i])
-	}
-	borders = append(borders, randBorders...)
-
-	for i := 0; i < len(cases); i += 2 {
-		for j := 0; j < len(borders); j += 2 {
-			num, err := Integrate(cases[i], borders[j], borders[j+1], scheme)
-			ana := cases[i+1](borders[j+1]) - cases[i+1](borders[j])
-			if err != nil {
-				t.Error(fmt.Sprintf("error: \"%v\" (%v, analytic: %v, stats: %v)", err, num, ana, scheme.Stats()), i/2, j/2)
-			} else if math.Abs(ana-num) > scheme.Accuracy(nil) {
-				t.Error(fmt.Sprintf("result %v is not approximately %v (stats: %v)", num, ana, scheme.Stats()), i/2, j/2)
-			}
-		}
-	}
-}
-
 // Ensure step limit and statistic function as
 // advertised.
 func helperTestLimits(scheme Integral, min int, t *testing.T) {
@@ -53,7 +53,7 @@ func (simp *simpsonIntegral) Integrate(a, b float64) (float64, error) {
 	defer simp.lock.RUnlock()
 
 	if simp.steps < 3 && simp.steps >= 0 {
-		simp.stats = &Stats{0, 0, ErrorMinSteps}
+		simp.stats = &Stats{Error: ErrorMinSteps}
 		return 0, ErrorMinSteps
 	}
 
@@ -89,7 +89,7 @@ func (simp *simpsonIntegral) Integrate(a, b float64) (float64, error) {
 	}
 
 	// Record statistics
-	simp.stats = &Stats{steps, math.Abs(integral - prevInt), nil}
+	simp.stats = &Stats{Steps: steps, Accuracy: math.Abs(integral - prevInt)}
 
 	if n <= 1<<5 {
 		// We are not confident in the result, unless we take 5 refining steps
@@ -2,11 +2,6 @@ package quad
 
 import "testing"
 
-func TestSimp(t *testing.T) {
-	scheme := NewSimpsonIntegral(16)
-	helperTestResults(scheme, t)
-}
-
 // Ensure step limit and statistic function as
 // advertised.
 func TestSimpLimit(t *testing.T) {
@@ -0,0 +1,62 @@
+package quad_test
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/dyedgreen/comp-phys/pkg/casino"
+	"github.com/dyedgreen/comp-phys/pkg/quad"
+	"github.com/dyedgreen/comp-phys/pkg/quad/testfuncs"
+)
+
+// Unit test helpers
+
+func helperTestSuite(scheme quad.Integral, cases []testfuncs.Case, t *testing.T) {
+	// Run every case over a few random ranges
+	rand.Seed(42)
+	for i := 0; i < 5; i++ {
+		a := rand.Float64()*15 - 10
+		b := a + rand.Float64()*(5-a)
+		rescaled := make([]testfuncs.Case, len(cases))
+		for j := range cases {
+			rescaled[j] = testfuncs.Rescale(cases[j], a, b)
+		}
+		for _, res := range testfuncs.Run(scheme, rescaled) {
+			if !res.Pass() {
+				t.Error(fmt.Sprintf("[%v, %v] %v", a, b, res))
+			}
+		}
+	}
+}
+
+// The non-smooth Genz families are not included,
+// since the quadrature convergence checks are not
+// reliable for them.
+var smooth = []testfuncs.Case{
+	testfuncs.Oscillatory(5, 0.3),
+	testfuncs.ProductPeak(5, 0.3),
+	testfuncs.CornerPeak(5, 0.3),
+	testfuncs.Gaussian(5, 0.3),
+}
+
+func TestTrap(t *testing.T) {
+	scheme := quad.NewTrapezoidalIntegral(16)
+	helperTestSuite(scheme, smooth, t)
+}
+
+func TestSimp(t *testing.T) {
+	scheme := quad.NewSimpsonIntegral(16)
+	helperTestSuite(scheme, smooth, t)
+}
+
+func TestMont(t *testing.T) {
+	scheme := quad.NewUniformMonteCarloIntegral(1000, 64, casino.Noise(64), nil)
+	acc := 1e-1 // Monte Carlo takes a while to converge
+	scheme.Accuracy(&acc)
+	helperTestSuite(scheme, []testfuncs.Case{
+		testfuncs.Oscillatory(5, 0.3),
+		testfuncs.CornerPeak(5, 0.3),
+		testfuncs.Gaussian(5, 0.3),
+	}, t)
+}
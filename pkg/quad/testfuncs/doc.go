@@ -0,0 +1,4 @@
+// Package testfuncs provides parameterised integrands with
+// known analytic integrals, and a harness to run quad schemes
+// against them.
+package testfuncs
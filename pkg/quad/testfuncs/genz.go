@@ -0,0 +1,170 @@
+package testfuncs
+
+import (
+	"math"
+)
+
+// Case is an integrand together with
+// its bounds and the exact value of
+// the integral over those bounds.
+type Case struct {
+	Name     string
+	Function func(float64) float64
+	A, B     float64
+	Integral float64
+}
+
+// The families below are the one dimensional
+// versions of the test integrands presented in:
+//
+//     Genz, A. (1984). "Testing Multidimensional Integration Routines".
+//     Proc. of International Conference on Tools, Methods and Languages
+//     for Scientific and Engineering Computation, 81–94.
+//
+// All of them are defined on [0, 1]. The parameter
+// c controls how difficult the integrand is (larger
+// is harder), w shifts the interesting feature and
+// should be in [0, 1].
+
+// Oscillatory returns the Genz oscillatory
+// integrand cos(2 pi w + c x).
+func Oscillatory(c, w float64) Case {
+	return Case{
+		Name: "oscillatory",
+		Function: func(x float64) float64 {
+			return math.Cos(2*math.Pi*w + c*x)
+		},
+		A: 0, B: 1,
+		Integral: (math.Sin(2*math.Pi*w+c) - math.Sin(2*math.Pi*w)) / c,
+	}
+}
+
+// ProductPeak returns the Genz product peak
+// integrand 1 / (c^-2 + (x - w)^2).
+func ProductPeak(c, w float64) Case {
+	return Case{
+		Name: "product peak",
+		Function: func(x float64) float64 {
+			return 1 / (1/(c*c) + (x-w)*(x-w))
+		},
+		A: 0, B: 1,
+		Integral: c * (math.Atan(c*(1-w)) + math.Atan(c*w)),
+	}
+}
+
+// CornerPeak returns the Genz corner peak
+// integrand (1 + c x)^-2.
+func CornerPeak(c, _ float64) Case {
+	return Case{
+		Name: "corner peak",
+		Function: func(x float64) float64 {
+			return 1 / ((1 + c*x) * (1 + c*x))
+		},
+		A: 0, B: 1,
+		Integral: 1 / (1 + c),
+	}
+}
+
+// Gaussian returns the Genz Gaussian
+// integrand exp(-c^2 (x - w)^2).
+func Gaussian(c, w float64) Case {
+	return Case{
+		Name: "gaussian",
+		Function: func(x float64) float64 {
+			return math.Exp(-c * c * (x - w) * (x - w))
+		},
+		A: 0, B: 1,
+		Integral: math.SqrtPi / (2 * c) * (math.Erf(c*(1-w)) + math.Erf(c*w)),
+	}
+}
+
+// C0 returns the Genz continuous integrand
+// exp(-c |x - w|), which has a kink at w.
+func C0(c, w float64) Case {
+	return Case{
+		Name: "C0",
+		Function: func(x float64) float64 {
+			return math.Exp(-c * math.Abs(x-w))
+		},
+		A: 0, B: 1,
+		Integral: (2 - math.Exp(-c*w) - math.Exp(-c*(1-w))) / c,
+	}
+}
+
+// Discontinuous returns the Genz discontinuous
+// integrand, which is exp(c x) for x <= w and
+// zero otherwise.
+func Discontinuous(c, w float64) Case {
+	return Case{
+		Name: "discontinuous",
+		Function: func(x float64) float64 {
+			if x > w {
+				return 0
+			}
+			return math.Exp(c * x)
+		},
+		A: 0, B: 1,
+		Integral: (math.Exp(c*w) - 1) / c,
+	}
+}
+
+// Genz returns all six Genz families for
+// the given parameters.
+func Genz(c, w float64) []Case {
+	return []Case{
+		Oscillatory(c, w),
+		ProductPeak(c, w),
+		CornerPeak(c, w),
+		Gaussian(c, w),
+		C0(c, w),
+		Discontinuous(c, w),
+	}
+}
+
+// Singular returns the integrand |x - w|^-1/2
+// on [0, 1], which has an integrable singularity
+// at w. The singular point itself evaluates to
+// zero, so schemes which evaluate it remain finite.
+func Singular(w float64) Case {
+	return Case{
+		Name: "singular",
+		Function: func(x float64) float64 {
+			if x == w {
+				return 0
+			}
+			return 1 / math.Sqrt(math.Abs(x-w))
+		},
+		A: 0, B: 1,
+		Integral: 2 * (math.Sqrt(w) + math.Sqrt(1-w)),
+	}
+}
+
+// InfiniteRange returns the integrand
+// exp(-c^2 x^2) on [0, +infinity). Note that
+// only schemes which support infinite bounds
+// are able to evaluate this case.
+func InfiniteRange(c float64) Case {
+	return Case{
+		Name: "infinite range",
+		Function: func(x float64) float64 {
+			return math.Exp(-c * c * x * x)
+		},
+		A: 0, B: math.Inf(1),
+		Integral: math.SqrtPi / (2 * c),
+	}
+}
+
+// Rescale maps a case defined on [0, 1] onto
+// [a, b]. The integrand is stretched, so that
+// the exact integral is scaled by (b - a).
+func Rescale(c Case, a, b float64) Case {
+	fn, ca, cb := c.Function, c.A, c.B
+	return Case{
+		Name: c.Name,
+		Function: func(x float64) float64 {
+			return fn(ca + (cb-ca)*(x-a)/(b-a))
+		},
+		A: a, B: b,
+		Integral: c.Integral * (b - a) / (cb - ca),
+	}
+}
@@ -0,0 +1,70 @@
+package testfuncs
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/dyedgreen/comp-phys/pkg/quad"
+)
+
+// Make sure the analytic integrals are
+// correct, by comparing against Simpson's
+// rule at high accuracy.
+func TestGenz(t *testing.T) {
+	params := []float64{
+		// c, w pairs
+		1, 0.5,
+		5, 0.3,
+		10, 0.9,
+	}
+	for i := 0; i < len(params); i += 2 {
+		cases := append(Genz(params[i], params[i+1]), Rescale(Gaussian(params[i], params[i+1]), -3, 4))
+		for _, res := range Run(nil, cases) {
+			if res.Error > 1e-4*math.Max(1, math.Abs(res.Case.Integral)) {
+				t.Error(fmt.Sprintf("analytic integral is wrong: %v", res))
+			}
+		}
+	}
+}
+
+// The singular and infinite cases can not be
+// integrated directly, so they are checked after
+// substituting x = w -/+ u^2 around the singularity
+// and cutting the range where exp(-c^2 x^2) < 1e-300.
+// The substituted integrand is 2 apart from u = 0, where
+// the singular case evaluates to zero (w - u^2 rounds to
+// w for small u), so [0, eps] is integrated exactly.
+func TestSingularInfinite(t *testing.T) {
+	acc := 1e-9
+	integrate := func(fn func(float64) float64, a, b float64) float64 {
+		scheme := quad.NewSimpsonIntegral(1)
+		scheme.Accuracy(&acc)
+		val, err := quad.Integrate(fn, a, b, scheme)
+		if err != nil {
+			t.Fatal(fmt.Sprintf("%v on [%v, %v]", err, a, b))
+		}
+		return val
+	}
+
+	for _, w := range []float64{0, 0.2, 0.5, 0.75, 1} {
+		c := Singular(w)
+		const eps = 1e-6
+		var left, right float64
+		if w > 0 {
+			left = 2*eps + integrate(func(u float64) float64 { return 2 * u * c.Function(w-u*u) }, eps, math.Sqrt(w))
+		}
+		if w < 1 {
+			right = 2*eps + integrate(func(u float64) float64 { return 2 * u * c.Function(w+u*u) }, eps, math.Sqrt(1-w))
+		}
+		if math.Abs(left+right-c.Integral) > 1e-7 {
+			t.Error(fmt.Sprintf("analytic integral %v of %v should be %v", c.Integral, c.Name, left+right))
+		}
+	}
+	for _, k := range []float64{0.5, 1, 10} {
+		c := InfiniteRange(k)
+		if val := integrate(c.Function, 0, 27/k); math.Abs(val-c.Integral) > 1e-7 {
+			t.Error(fmt.Sprintf("analytic integral %v of %v should be %v", c.Integral, c.Name, val))
+		}
+	}
+}
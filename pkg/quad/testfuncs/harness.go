@@ -0,0 +1,60 @@
+package testfuncs
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/dyedgreen/comp-phys/pkg/quad"
+)
+
+// Result reports the performance of a
+// scheme on a single test case.
+type Result struct {
+	Case Case
+	// Value returned by the scheme
+	Value float64
+	// Achieved and requested accuracy
+	Error, Accuracy float64
+	// Function evaluations used
+	Steps int
+	// Error returned by the scheme
+	Err error
+}
+
+// Pass returns true if the scheme returned
+// without error and achieved the requested
+// accuracy.
+func (r Result) Pass() bool {
+	return r.Err == nil && r.Error <= r.Accuracy
+}
+
+func (r Result) String() string {
+	return fmt.Sprintf("%v: value %v (exact %v), error %v (requested %v), %v steps, err: %v",
+		r.Case.Name, r.Value, r.Case.Integral, r.Error, r.Accuracy, r.Steps, r.Err)
+}
+
+// Run integrates every case using scheme
+// and reports the achieved error versus
+// the accuracy requested from the scheme.
+// If no scheme is given, Simpson's rule is
+// used, as for quad.Integrate.
+func Run(scheme quad.Integral, cases []Case) []Result {
+	if scheme == nil {
+		scheme = quad.NewSimpsonIntegral(1)
+	}
+	results := make([]Result, len(cases))
+	for i, c := range cases {
+		val, err := quad.Integrate(c.Function, c.A, c.B, scheme)
+		results[i] = Result{
+			Case:     c,
+			Value:    val,
+			Error:    math.Abs(val - c.Integral),
+			Accuracy: scheme.Accuracy(nil),
+			Err:      err,
+		}
+		if stats := scheme.Stats(); stats != nil {
+			results[i].Steps = stats.Steps
+		}
+	}
+	return results
+}
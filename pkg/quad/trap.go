@@ -82,7 +82,7 @@ func (trap *trapezoidalIntegral) Integrate(a, b float64) (float64, error) {
 	defer trap.lock.RUnlock()
 
 	if trap.steps < 2 && trap.steps >= 0 {
-		trap.stats = &Stats{0, 0, ErrorMinSteps}
+		trap.stats = &Stats{Error: ErrorMinSteps}
 		return 0, ErrorMinSteps
 	}
 
@@ -112,7 +112,7 @@ func (trap *trapezoidalIntegral) Integrate(a, b float64) (float64, error) {
 	}
 
 	// Record statistics
-	trap.stats = &Stats{steps, math.Abs(integral - prevInt), nil}
+	trap.stats = &Stats{Steps: steps, Accuracy: math.Abs(integral - prevInt)}
 
 	if n <= 1<<5 {
 		// We are not confident in the result, unless we take 5 refining steps
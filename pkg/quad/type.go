@@ -1,5 +1,7 @@
 package quad
 
+import "time"
+
 // To be used by all implementations in this package
 const defaultAccuracy = 1e-5
 const defaultMaxStep = 1e6
@@ -40,6 +42,12 @@ type Stats struct {
 	Steps    int
 	Accuracy float64
 	Error    error
+	// Timing of Monte-Carlo schemes, see
+	// casino.Stats. Rate is the number of
+	// steps per second.
+	Time     time.Duration
+	BusyTime []time.Duration
+	Rate     float64
 }
 
 // Integrate fn between a, b using the supplied scheme. If no scheme is
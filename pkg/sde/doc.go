@@ -0,0 +1,12 @@
+// Package sde simulates Itô stochastic differential
+// equations of the form
+//
+//     dX = a(X, t) dt + b(X, t) dW
+//
+// where W is a Wiener process. Scalar equations are
+// described by SDE, and systems with diagonal noise
+// (i.e. an independent Wiener process for every
+// component) by System. For example Brownian motion,
+// Ornstein-Uhlenbeck processes and underdamped or
+// overdamped Langevin dynamics.
+package sde
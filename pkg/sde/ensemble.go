@@ -0,0 +1,149 @@
+package sde
+
+import (
+	"math"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/dyedgreen/comp-phys/pkg/casino"
+)
+
+// An ensemble of paths of a state with dim
+// components, which is the common part of
+// Simulation and SystemSimulation
+type ensemble struct {
+	x0                    []float64
+	t0, step              float64
+	steps, paths, workers int
+	seeds                 []uint64
+	// Number of standard normal deviates
+	// used per step
+	noise int
+	// Returns a function advancing x in place at
+	// time t, given the deviates u. Every worker
+	// creates its own, so it may keep scratch space.
+	stepper func() func(x []float64, t float64, u []float64)
+}
+
+// Number of workers, which defaults to
+// GOMAXPROCS and is at most one per path
+func (e *ensemble) workerCount() int {
+	workers := e.workers
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > e.paths {
+		workers = e.paths
+	}
+	return workers
+}
+
+// Run fn for contiguous blocks of paths
+// on concurrent workers
+func (e *ensemble) parallel(fn func(worker, from, to int)) {
+	workers := e.workerCount()
+	wait := sync.WaitGroup{}
+	wait.Add(workers)
+	for k := 0; k < workers; k++ {
+		go func(k int) {
+			defer wait.Done()
+			fn(k, k*e.paths/workers, (k+1)*e.paths/workers)
+		}(k)
+	}
+	wait.Wait()
+}
+
+// Integrate the paths from, ..., to-1 and pass
+// every state to record, which must not retain x
+func (e *ensemble) run(from, to int, record func(path, step int, x []float64)) {
+	advance := e.stepper()
+	x := make([]float64, len(e.x0))
+	u := make([]float64, e.noise)
+	for p := from; p < to; p++ {
+		noise := casino.NewSampler(casino.NormalDist{Mu: 0, Sigma: 1}, e.seeds[p])
+		copy(x, e.x0)
+		record(p, 0, x)
+		for i := 0; i < e.steps; i++ {
+			for k := range u {
+				u[k] = noise.Sample()
+			}
+			advance(x, e.t0+float64(i)*e.step, u)
+			record(p, i+1, x)
+		}
+	}
+}
+
+// Mean and variance of every component at
+// every step, where res[i][k] is component k
+// at time t0 + i step
+func (e *ensemble) statistics() [][]casino.Result {
+	start := time.Now()
+	dim := len(e.x0)
+
+	// Welford's update for every worker, step
+	// and component
+	type moments struct {
+		n        []int
+		mean, m2 []float64
+		busyTime time.Duration
+	}
+	workers := make([]moments, e.workerCount())
+	e.parallel(func(k, from, to int) {
+		t0 := time.Now()
+		m := moments{
+			n:    make([]int, (e.steps+1)*dim),
+			mean: make([]float64, (e.steps+1)*dim),
+			m2:   make([]float64, (e.steps+1)*dim),
+		}
+		e.run(from, to, func(_, step int, x []float64) {
+			for c, xc := range x {
+				i := step*dim + c
+				m.n[i]++
+				delta := xc - m.mean[i]
+				m.mean[i] += delta / float64(m.n[i])
+				m.m2[i] += delta * (xc - m.mean[i])
+			}
+		})
+		m.busyTime = time.Since(t0)
+		workers[k] = m
+	})
+
+	// Combine workers (Chan et al., see casino.Expectation)
+	var busyTime []time.Duration
+	for _, m := range workers {
+		busyTime = append(busyTime, m.busyTime)
+	}
+	wall := time.Since(start)
+	res := make([][]casino.Result, e.steps+1)
+	for step := range res {
+		res[step] = make([]casino.Result, dim)
+		for c := range res[step] {
+			i := step*dim + c
+			var n int
+			var mean, m2 float64
+			for _, m := range workers {
+				if m.n[i] == 0 {
+					continue
+				}
+				total := n + m.n[i]
+				delta := m.mean[i] - mean
+				mean += delta * float64(m.n[i]) / float64(total)
+				m2 += m.m2[i] + delta*delta*float64(n)*float64(m.n[i])/float64(total)
+				n = total
+			}
+			r := &res[step][c]
+			r.Value = mean
+			if n > 1 {
+				r.Variance = m2 / float64(n-1)
+			}
+			r.StdErr = math.Sqrt(r.Variance / float64(n))
+			r.BatchStdErr, r.Tau, r.ESS = r.StdErr, 1, float64(n)
+			r.Trials = n
+			r.Time = wall
+			r.BusyTime = busyTime
+			r.Rate = float64(e.paths*e.steps) / wall.Seconds()
+		}
+	}
+	return res
+}
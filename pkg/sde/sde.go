@@ -0,0 +1,121 @@
+package sde
+
+import (
+	"math"
+)
+
+// Scheme selects the numerical integration
+// scheme, see:
+//
+//     Kloeden, P. E.; Platen, E. (1992). "Numerical Solution of
+//     Stochastic Differential Equations". Springer.
+type Scheme int
+
+const (
+	// Euler-Maruyama scheme, strong order 0.5
+	EulerMaruyama Scheme = iota
+	// Milstein scheme, strong order 1
+	Milstein
+	// Itô-Taylor scheme, strong order 1.5
+	StrongOrder15
+)
+
+func (s Scheme) String() string {
+	switch s {
+	case EulerMaruyama:
+		return "Euler-Maruyama"
+	case Milstein:
+		return "Milstein"
+	case StrongOrder15:
+		return "strong order 1.5"
+	default:
+		return "unknown"
+	}
+}
+
+// SDE describes an Itô equation
+//
+//     dX = Drift(X, t) dt + Diffusion(X, t) dW
+//
+// The higher order schemes need partial derivatives
+// of the coefficients, with respect to x (X, XX) and
+// t (T). Milstein uses DiffusionX, the strong order
+// 1.5 scheme uses all of them. Derivatives which are
+// nil are approximated by finite differences.
+type SDE struct {
+	Drift, Diffusion func(x, t float64) float64
+
+	DriftX, DriftXX, DriftT             func(x, t float64) float64
+	DiffusionX, DiffusionXX, DiffusionT func(x, t float64) float64
+}
+
+// Relative steps for finite differences
+const firstStep = 1e-5
+const secondStep = 1e-4
+
+// Partial derivatives of f at (x, t), using
+// the given function if it is not nil
+func dx(f, given func(x, t float64) float64, x, t float64) float64 {
+	if given != nil {
+		return given(x, t)
+	}
+	h := firstStep * (1 + math.Abs(x))
+	return (f(x+h, t) - f(x-h, t)) / (2 * h)
+}
+
+func dxx(f, given func(x, t float64) float64, x, t float64) float64 {
+	if given != nil {
+		return given(x, t)
+	}
+	h := secondStep * (1 + math.Abs(x))
+	return (f(x+h, t) - 2*f(x, t) + f(x-h, t)) / (h * h)
+}
+
+// The time derivative uses a one sided (second order)
+// difference, since coefficients may not be defined
+// before the initial time (e.g. sqrt(t) at t = 0)
+func dt(f, given func(x, t float64) float64, x, t float64) float64 {
+	if given != nil {
+		return given(x, t)
+	}
+	h := firstStep * (1 + math.Abs(t))
+	return (4*f(x, t+h) - 3*f(x, t) - f(x, t+2*h)) / (2 * h)
+}
+
+// Advance x at time t by a step of size h, where
+// u1, u2 are independent standard normal deviates.
+// The Wiener increment is dW = u1 sqrt(h), and the
+// strong order 1.5 scheme also uses the double
+// integral dZ = \int_t^{t+h} \int_t^s dW ds.
+func (s *SDE) step(scheme Scheme, x, t, h, u1, u2 float64) float64 {
+	a, b := s.Drift(x, t), s.Diffusion(x, t)
+	dW := u1 * math.Sqrt(h)
+	y := x + a*h + b*dW
+	if scheme == EulerMaruyama {
+		return y
+	}
+
+	bx := dx(s.Diffusion, s.DiffusionX, x, t)
+	// L1 b = b b'
+	y += 0.5 * b * bx * (dW*dW - h)
+	if scheme == Milstein {
+		return y
+	}
+
+	dZ := 0.5 * h * math.Sqrt(h) * (u1 + u2/math.Sqrt(3))
+	ax := dx(s.Drift, s.DriftX, x, t)
+	axx := dxx(s.Drift, s.DriftXX, x, t)
+	bxx := dxx(s.Diffusion, s.DiffusionXX, x, t)
+	at := dt(s.Drift, s.DriftT, x, t)
+	bt := dt(s.Diffusion, s.DiffusionT, x, t)
+
+	// L0 f = f_t + a f' + b^2 f'' / 2 and L1 f = b f'
+	l0a := at + a*ax + 0.5*b*b*axx
+	l0b := bt + a*bx + 0.5*b*b*bxx
+	l1a := b * ax
+	l1l1b := b * (bx*bx + b*bxx)
+
+	y += l1a*dZ + l0b*(dW*h-dZ) + 0.5*l0a*h*h
+	y += 0.5 * l1l1b * (dW*dW/3 - h) * dW
+	return y
+}
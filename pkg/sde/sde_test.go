@@ -0,0 +1,213 @@
+package sde
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/dyedgreen/comp-phys/pkg/casino"
+)
+
+// Ensemble statistics of Brownian motion and of
+// an Ornstein-Uhlenbeck process match the exact
+// moments for all schemes.
+func TestStatistics(t *testing.T) {
+	const theta, sigma = 2, 0.5
+	processes := []SDE{
+		{
+			Drift:     func(x, t float64) float64 { return 0 },
+			Diffusion: func(x, t float64) float64 { return sigma },
+		},
+		{
+			Drift:     func(x, t float64) float64 { return -theta * x },
+			Diffusion: func(x, t float64) float64 { return sigma },
+		},
+	}
+	means := []func(t float64) float64{
+		func(t float64) float64 { return 1 },
+		func(t float64) float64 { return math.Exp(-theta * t) },
+	}
+	variances := []func(t float64) float64{
+		func(t float64) float64 { return sigma * sigma * t },
+		func(t float64) float64 { return sigma * sigma / (2 * theta) * (1 - math.Exp(-2*theta*t)) },
+	}
+
+	for i, sde := range processes {
+		for _, scheme := range []Scheme{EulerMaruyama, Milstein, StrongOrder15} {
+			sim := Simulation{
+				SDE:    sde,
+				Scheme: scheme,
+				X0:     1,
+				Step:   0.01,
+				Steps:  200,
+				Paths:  20000,
+				Source: casino.NewSeedSource(casino.Seed()),
+			}
+			res, err := sim.Statistics()
+			if err != nil {
+				t.Fatal(err)
+			}
+			ts := sim.Times()
+			for _, k := range []int{0, 50, 200} {
+				if math.Abs(res[k].Value-means[i](ts[k])) > 5*res[k].StdErr+1e-3 {
+					t.Error(fmt.Sprintf("%v, %v: mean %v at %v should be %v", i, scheme, res[k].Value, ts[k], means[i](ts[k])))
+				}
+				if v := variances[i](ts[k]); math.Abs(res[k].Variance-v) > 0.05*v+1e-12 {
+					t.Error(fmt.Sprintf("%v, %v: variance %v at %v should be %v", i, scheme, res[k].Variance, ts[k], v))
+				}
+			}
+			if res[200].Trials != 20000 || res[200].Time <= 0 {
+				t.Error(fmt.Sprintf("wrong stats %v", res[200].Stats))
+			}
+		}
+	}
+}
+
+// Strong errors against the exact solution of
+// geometric Brownian motion decrease with the
+// order of the scheme. Coarse increments are
+// built from the same fine Wiener path.
+func TestStrongOrder(t *testing.T) {
+	const mu, sigma = 1.0, 0.8
+	const fine = 1024
+	const paths = 400
+	gbm := SDE{
+		Drift:     func(x, t float64) float64 { return mu * x },
+		Diffusion: func(x, t float64) float64 { return sigma * x },
+	}
+	exact := gbm
+	exact.DriftX = func(x, t float64) float64 { return mu }
+	exact.DriftXX = func(x, t float64) float64 { return 0 }
+	exact.DriftT = func(x, t float64) float64 { return 0 }
+	exact.DiffusionX = func(x, t float64) float64 { return sigma }
+	exact.DiffusionXX = func(x, t float64) float64 { return 0 }
+	exact.DiffusionT = func(x, t float64) float64 { return 0 }
+
+	coarse := []int{16, 64}
+	orders := []float64{0.5, 1, 1.5}
+	errs := make([][]float64, len(orders))
+	for s := range errs {
+		errs[s] = make([]float64, len(coarse))
+	}
+
+	noise := casino.NewSampler(casino.NormalDist{Mu: 0, Sigma: 1}, casino.Seed())
+	h := 1.0 / fine
+	for p := 0; p < paths; p++ {
+		// Fine Wiener increments and double integrals
+		dW, dZ := make([]float64, fine), make([]float64, fine)
+		var w float64
+		for j := range dW {
+			u1, u2 := noise.Sample(), noise.Sample()
+			dW[j] = u1 * math.Sqrt(h)
+			dZ[j] = 0.5 * h * math.Sqrt(h) * (u1 + u2/math.Sqrt(3))
+			w += dW[j]
+		}
+		solution := math.Exp((mu-sigma*sigma/2)*1 + sigma*w)
+
+		for c, n := range coarse {
+			H, m := 1.0/float64(n), fine/n
+			for s, scheme := range []Scheme{EulerMaruyama, Milstein, StrongOrder15} {
+				for _, sde := range []SDE{gbm, exact} {
+					x := 1.0
+					for i := 0; i < n; i++ {
+						var W, Z float64
+						for j := i * m; j < (i+1)*m; j++ {
+							Z += W*h + dZ[j]
+							W += dW[j]
+						}
+						u1 := W / math.Sqrt(H)
+						u2 := math.Sqrt(3) * (2*Z/(H*math.Sqrt(H)) - u1)
+						x = sde.step(scheme, x, float64(i)*H, H, u1, u2)
+					}
+					errs[s][c] += math.Abs(x-solution) / paths / 2
+				}
+			}
+		}
+	}
+	for s, order := range orders {
+		// Step sizes differ by a factor of 4
+		if rate := math.Log(errs[s][0]/errs[s][1]) / math.Log(4); math.Abs(rate-order) > 0.25 {
+			t.Error(fmt.Sprintf("scheme %v converges with order %v, should be %v (%v)", Scheme(s), rate, order, errs[s]))
+		}
+	}
+}
+
+// Coefficients may not be defined before T0, e.g.
+// dX = (1 + t^1.5) dW, with Var X(1) = 2.05.
+func TestTimeDependent(t *testing.T) {
+	sim := Simulation{
+		SDE: SDE{
+			Drift:     func(x, t float64) float64 { return 0 },
+			Diffusion: func(x, t float64) float64 { return 1 + math.Pow(t, 1.5) },
+		},
+		Scheme: StrongOrder15,
+		Step:   0.01,
+		Steps:  100,
+		Paths:  20000,
+		Source: casino.NewSeedSource(casino.Seed()),
+	}
+	res, err := sim.Statistics()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := res[100].Variance; math.IsNaN(v) || math.Abs(v-2.05) > 0.05*2.05 {
+		t.Error(fmt.Sprintf("variance %v should be 2.05", v))
+	}
+}
+
+func TestEnsemble(t *testing.T) {
+	sim := Simulation{
+		SDE: SDE{
+			Drift:     func(x, t float64) float64 { return -x + math.Sin(t) },
+			Diffusion: func(x, t float64) float64 { return 0.3 * (1 + x*x) / (1 + x*x/2) },
+		},
+		Scheme: StrongOrder15,
+		Step:   0.05,
+		Steps:  40,
+		Paths:  37,
+		Seeds:  casino.Noise(37),
+	}
+	var ensembles [][][]float64
+	for _, workers := range []int{1, 5} {
+		sim.Workers = workers
+		paths, err := sim.Ensemble()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(paths) != 37 || len(paths[0]) != 41 {
+			t.Fatal(fmt.Sprintf("wrong ensemble size %v x %v", len(paths), len(paths[0])))
+		}
+		ensembles = append(ensembles, paths)
+	}
+	for p := range ensembles[0] {
+		for i := range ensembles[0][p] {
+			if ensembles[0][p][i] != ensembles[1][p][i] {
+				t.Fatal("paths should not depend on the number of workers")
+			}
+		}
+	}
+
+	// Statistics match the ensemble
+	res, _ := sim.Statistics()
+	for _, i := range []int{0, 20, 40} {
+		var mean float64
+		for p := range ensembles[0] {
+			mean += ensembles[0][p][i] / 37
+		}
+		if math.Abs(res[i].Value-mean) > 1e-12 {
+			t.Error(fmt.Sprintf("mean %v at %v should be %v", res[i].Value, i, mean))
+		}
+	}
+
+	bad := []Simulation{
+		{SDE: sim.SDE, Step: 0.1, Steps: 10, Paths: 2},
+		{SDE: sim.SDE, Step: 0, Steps: 10, Paths: 2, Seeds: casino.Noise(2)},
+		{SDE: SDE{Drift: sim.Drift}, Step: 0.1, Steps: 10, Paths: 2, Seeds: casino.Noise(2)},
+		{SDE: sim.SDE, Scheme: 7, Step: 0.1, Steps: 10, Paths: 2, Seeds: casino.Noise(2)},
+	}
+	for i := range bad {
+		if _, err := bad[i].Ensemble(); err == nil {
+			t.Error(fmt.Sprintf("configuration %v should be rejected", i))
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package sde
+
+import (
+	"errors"
+
+	"github.com/dyedgreen/comp-phys/pkg/casino"
+)
+
+// Simulation integrates an ensemble of paths
+// of an SDE from X0 at T0, taking Steps steps
+// of size Step.
+//
+// Every path uses its own random stream, so
+// the results do not depend on the number of
+// Workers, which run concurrently.
+type Simulation struct {
+	SDE
+	Scheme Scheme
+
+	X0, T0 float64
+	Step   float64
+	Steps  int
+	Paths  int
+
+	// Number of concurrent workers, which
+	// defaults to GOMAXPROCS.
+	Workers int
+
+	// Seeds for every path, or a
+	// Source to draw them from
+	Seeds  []uint64
+	Source *casino.SeedSource
+}
+
+// Times returns the times at which the paths
+// are recorded, i.e. T0 + i Step.
+func (sim *Simulation) Times() []float64 {
+	return times(sim.T0, sim.Step, sim.Steps)
+}
+
+func times(t0, step float64, steps int) []float64 {
+	ts := make([]float64, steps+1)
+	for i := range ts {
+		ts[i] = t0 + float64(i)*step
+	}
+	return ts
+}
+
+// Check the configuration and return the ensemble
+func (sim *Simulation) ensemble() (*ensemble, error) {
+	seeds := sim.Seeds
+	if seeds == nil && sim.Source != nil {
+		seeds = sim.Source.Seeds(sim.Paths)
+	}
+	switch {
+	case sim.Drift == nil || sim.Diffusion == nil:
+		return nil, errors.New("need to provide Drift and Diffusion")
+	case sim.Paths < 1 || sim.Steps < 1:
+		return nil, errors.New("need to simulate at least one path and step")
+	case !(sim.Step > 0):
+		return nil, errors.New("step size must be positive")
+	case len(seeds) != sim.Paths:
+		return nil, errors.New("need to provide a seed for every path, or a source")
+	case sim.Scheme < EulerMaruyama || sim.Scheme > StrongOrder15:
+		return nil, errors.New("unknown scheme")
+	}
+	noise := 1
+	if sim.Scheme == StrongOrder15 {
+		noise = 2
+	}
+	return &ensemble{
+		x0:      []float64{sim.X0},
+		t0:      sim.T0,
+		step:    sim.Step,
+		steps:   sim.Steps,
+		paths:   sim.Paths,
+		workers: sim.Workers,
+		seeds:   seeds,
+		noise:   noise,
+		stepper: func() func(x []float64, t float64, u []float64) {
+			return func(x []float64, t float64, u []float64) {
+				var u2 float64
+				if len(u) > 1 {
+					u2 = u[1]
+				}
+				x[0] = sim.step(sim.Scheme, x[0], t, sim.Step, u[0], u2)
+			}
+		},
+	}, nil
+}
+
+// Ensemble returns all paths, where paths[p][i] is
+// the state of path p at time T0 + i Step. This needs
+// O(Paths * Steps) memory, see Statistics.
+func (sim *Simulation) Ensemble() ([][]float64, error) {
+	e, err := sim.ensemble()
+	if err != nil {
+		return nil, err
+	}
+	paths := make([][]float64, sim.Paths)
+	for p := range paths {
+		paths[p] = make([]float64, sim.Steps+1)
+	}
+	e.parallel(func(_, from, to int) {
+		e.run(from, to, func(path, step int, x []float64) {
+			paths[path][step] = x[0]
+		})
+	})
+	return paths, nil
+}
+
+// Statistics returns the mean and variance of the
+// ensemble at every time T0 + i Step, without storing
+// the paths. The mean is the Value of every result,
+// with its StdErr.
+func (sim *Simulation) Statistics() ([]casino.Result, error) {
+	e, err := sim.ensemble()
+	if err != nil {
+		return nil, err
+	}
+	stats := e.statistics()
+	res := make([]casino.Result, len(stats))
+	for i := range res {
+		res[i] = stats[i][0]
+	}
+	return res, nil
+}
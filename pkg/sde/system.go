@@ -0,0 +1,168 @@
+package sde
+
+import (
+	"errors"
+	"math"
+
+	"github.com/dyedgreen/comp-phys/pkg/casino"
+)
+
+// System describes a system of Itô equations with
+// diagonal noise
+//
+//     dX_k = Drift_k(X, t) dt + Diffusion_k(X, t) dW_k
+//
+// where the W_k are independent Wiener processes. For
+// example underdamped Langevin dynamics, where X holds
+// positions and velocities, and only the velocities
+// are driven by noise. The coefficients write their
+// components into dst.
+//
+// Milstein uses the derivative of every Diffusion_k
+// with respect to X_k, given by DiffusionX or else
+// approximated by central finite differences. The
+// scheme has strong order 1 if every Diffusion_k only
+// depends on X_k and t, otherwise the iterated integrals
+// of different Wiener processes would be needed. The
+// strong order 1.5 scheme is only available for scalar
+// equations, see SDE.
+type System struct {
+	Drift, Diffusion func(dst, x []float64, t float64)
+
+	DiffusionX func(dst, x []float64, t float64)
+}
+
+// Returns a function advancing x in place by a step
+// of size h, given standard normal deviates u for
+// every component
+func (s *System) stepper(scheme Scheme, dim int, h float64) func(x []float64, t float64, u []float64) {
+	a, b := make([]float64, dim), make([]float64, dim)
+	bx, y := make([]float64, dim), make([]float64, dim)
+	up, down := make([]float64, dim), make([]float64, dim)
+	sqrtH := math.Sqrt(h)
+	return func(x []float64, t float64, u []float64) {
+		s.Drift(a, x, t)
+		s.Diffusion(b, x, t)
+		if scheme == Milstein {
+			if s.DiffusionX != nil {
+				s.DiffusionX(bx, x, t)
+			} else {
+				copy(y, x)
+				for k := range y {
+					d := firstStep * (1 + math.Abs(x[k]))
+					y[k] = x[k] + d
+					s.Diffusion(up, y, t)
+					y[k] = x[k] - d
+					s.Diffusion(down, y, t)
+					y[k] = x[k]
+					bx[k] = (up[k] - down[k]) / (2 * d)
+				}
+			}
+		}
+		for k := range x {
+			dW := u[k] * sqrtH
+			x[k] += a[k]*h + b[k]*dW
+			if scheme == Milstein {
+				x[k] += 0.5 * b[k] * bx[k] * (dW*dW - h)
+			}
+		}
+	}
+}
+
+// SystemSimulation integrates an ensemble of paths
+// of a System from X0 at T0, taking Steps steps of
+// size Step. See Simulation, which this mirrors for
+// vector valued states.
+type SystemSimulation struct {
+	System
+	Scheme Scheme
+
+	X0    []float64
+	T0    float64
+	Step  float64
+	Steps int
+	Paths int
+
+	// Number of concurrent workers, which
+	// defaults to GOMAXPROCS.
+	Workers int
+
+	// Seeds for every path, or a
+	// Source to draw them from
+	Seeds  []uint64
+	Source *casino.SeedSource
+}
+
+// Times returns the times at which the paths
+// are recorded, i.e. T0 + i Step.
+func (sim *SystemSimulation) Times() []float64 {
+	return times(sim.T0, sim.Step, sim.Steps)
+}
+
+// Check the configuration and return the ensemble
+func (sim *SystemSimulation) ensemble() (*ensemble, error) {
+	seeds := sim.Seeds
+	if seeds == nil && sim.Source != nil {
+		seeds = sim.Source.Seeds(sim.Paths)
+	}
+	switch {
+	case sim.Drift == nil || sim.Diffusion == nil:
+		return nil, errors.New("need to provide Drift and Diffusion")
+	case len(sim.X0) == 0:
+		return nil, errors.New("need to provide the initial state X0")
+	case sim.Paths < 1 || sim.Steps < 1:
+		return nil, errors.New("need to simulate at least one path and step")
+	case !(sim.Step > 0):
+		return nil, errors.New("step size must be positive")
+	case len(seeds) != sim.Paths:
+		return nil, errors.New("need to provide a seed for every path, or a source")
+	case sim.Scheme == StrongOrder15:
+		return nil, errors.New("strong order 1.5 scheme is only available for scalar equations")
+	case sim.Scheme < EulerMaruyama || sim.Scheme > StrongOrder15:
+		return nil, errors.New("unknown scheme")
+	}
+	return &ensemble{
+		x0:      sim.X0,
+		t0:      sim.T0,
+		step:    sim.Step,
+		steps:   sim.Steps,
+		paths:   sim.Paths,
+		workers: sim.Workers,
+		seeds:   seeds,
+		noise:   len(sim.X0),
+		stepper: func() func(x []float64, t float64, u []float64) {
+			return sim.stepper(sim.Scheme, len(sim.X0), sim.Step)
+		},
+	}, nil
+}
+
+// Ensemble returns all paths, where paths[p][i][k] is
+// component k of path p at time T0 + i Step. This needs
+// O(Paths * Steps * len(X0)) memory, see Statistics.
+func (sim *SystemSimulation) Ensemble() ([][][]float64, error) {
+	e, err := sim.ensemble()
+	if err != nil {
+		return nil, err
+	}
+	paths := make([][][]float64, sim.Paths)
+	for p := range paths {
+		paths[p] = make([][]float64, sim.Steps+1)
+	}
+	e.parallel(func(_, from, to int) {
+		e.run(from, to, func(path, step int, x []float64) {
+			paths[path][step] = append([]float64(nil), x...)
+		})
+	})
+	return paths, nil
+}
+
+// Statistics returns the mean and variance of every
+// component of the ensemble, where res[i][k] is
+// component k at time T0 + i Step. See Simulation.
+func (sim *SystemSimulation) Statistics() ([][]casino.Result, error) {
+	e, err := sim.ensemble()
+	if err != nil {
+		return nil, err
+	}
+	return e.statistics(), nil
+}
@@ -0,0 +1,104 @@
+package sde
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/dyedgreen/comp-phys/pkg/casino"
+)
+
+// Underdamped Langevin dynamics of a harmonic
+// oscillator, whose mean follows the damped
+// oscillator and which relaxes to the Boltzmann
+// distribution with Var(x) = T/w^2, Var(v) = T.
+func TestSystemLangevin(t *testing.T) {
+	const gamma, omega, temp = 1.0, 2.0, 0.5
+	langevin := System{
+		Drift: func(dst, x []float64, t float64) {
+			dst[0] = x[1]
+			dst[1] = -gamma*x[1] - omega*omega*x[0]
+		},
+		Diffusion: func(dst, x []float64, t float64) {
+			dst[0] = 0
+			dst[1] = math.Sqrt(2 * gamma * temp)
+		},
+	}
+	for _, scheme := range []Scheme{EulerMaruyama, Milstein} {
+		sim := SystemSimulation{
+			System: langevin,
+			Scheme: scheme,
+			X0:     []float64{1, 0},
+			Step:   0.01,
+			Steps:  1500,
+			Paths:  4000,
+			Source: casino.NewSeedSource(casino.Seed()),
+		}
+		res, err := sim.Statistics()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res) != 1501 || len(res[0]) != 2 {
+			t.Fatal(fmt.Sprintf("wrong statistics size %v x %v", len(res), len(res[0])))
+		}
+
+		// Mean at t = 1
+		w := math.Sqrt(omega*omega - gamma*gamma/4)
+		mean := math.Exp(-gamma/2) * (math.Cos(w) + gamma/(2*w)*math.Sin(w))
+		if r := res[100][0]; math.Abs(r.Value-mean) > 5*r.StdErr+0.01 {
+			t.Error(fmt.Sprintf("%v: mean %v should be %v", scheme, r.Value, mean))
+		}
+
+		// Equilibrium at t = 15
+		for k, v := range []float64{temp / (omega * omega), temp} {
+			if r := res[1500][k]; math.Abs(r.Variance-v) > 0.1*v || math.Abs(r.Value) > 5*r.StdErr {
+				t.Error(fmt.Sprintf("%v: component %v has mean %v, variance %v, should be 0, %v", scheme, k, r.Value, r.Variance, v))
+			}
+		}
+	}
+}
+
+// A one dimensional system matches the
+// scalar simulation.
+func TestSystemScalar(t *testing.T) {
+	sde := SDE{
+		Drift:     func(x, t float64) float64 { return -x + math.Sin(t) },
+		Diffusion: func(x, t float64) float64 { return 0.3 * (1 + x*x) / (1 + x*x/2) },
+	}
+	system := System{
+		Drift:     func(dst, x []float64, t float64) { dst[0] = sde.Drift(x[0], t) },
+		Diffusion: func(dst, x []float64, t float64) { dst[0] = sde.Diffusion(x[0], t) },
+	}
+	seeds := casino.Noise(20)
+	for _, scheme := range []Scheme{EulerMaruyama, Milstein} {
+		scalar := Simulation{SDE: sde, Scheme: scheme, X0: 0.5, Step: 0.05, Steps: 40, Paths: 20, Seeds: seeds}
+		vector := SystemSimulation{System: system, Scheme: scheme, X0: []float64{0.5}, Step: 0.05, Steps: 40, Paths: 20, Seeds: seeds, Workers: 3}
+		a, err := scalar.Ensemble()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := vector.Ensemble()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for p := range a {
+			for i := range a[p] {
+				if math.Abs(a[p][i]-b[p][i][0]) > 1e-12 {
+					t.Fatal(fmt.Sprintf("%v: path %v differs at %v: %v, %v", scheme, p, i, a[p][i], b[p][i][0]))
+				}
+			}
+		}
+	}
+
+	bad := []SystemSimulation{
+		{System: system, X0: []float64{0}, Step: 0.1, Steps: 10, Paths: 2},
+		{System: system, Step: 0.1, Steps: 10, Paths: 2, Seeds: seeds[:2]},
+		{System: System{Drift: system.Drift}, X0: []float64{0}, Step: 0.1, Steps: 10, Paths: 2, Seeds: seeds[:2]},
+		{System: system, Scheme: StrongOrder15, X0: []float64{0}, Step: 0.1, Steps: 10, Paths: 2, Seeds: seeds[:2]},
+	}
+	for i := range bad {
+		if _, err := bad[i].Statistics(); err == nil {
+			t.Error(fmt.Sprintf("configuration %v should be rejected", i))
+		}
+	}
+}
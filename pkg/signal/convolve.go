@@ -20,7 +20,7 @@ func Convolve(a, b []float64) []float64 {
 	// Compute convolution
 	for i := range c {
 		for j := range b {
-			idx := j - i + off
+			idx := i - j + off
 			if idx < 0 || idx >= len(a) {
 				// This is equivalent to zero-padding a
 				continue
@@ -31,11 +31,13 @@ func Convolve(a, b []float64) []float64 {
 	return c
 }
 
-// FFTConvolve is equivalent to Convolve, but is
-// implemented using Fourier transforms. I.e.
-// this function computes (a * b) = F^-1(F(a) * F(b)),
+// FFTConvolve computes the same convolution as
+// Convolve, but is implemented using Fourier transforms.
+// I.e. this function computes (a * b) = F^-1(F(a) * F(b)),
 // where the multiplication is understood to be
-// element-wise. Note that this function expects
+// element-wise. Unlike Convolve, the convolution is
+// circular, so a wraps around instead of being zero
+// padded. Note that this function expects
 // len(a) == len(b).
 //
 // The runtime complexity of this function is O(N*ln(N))
@@ -48,9 +50,11 @@ func FFTConvolve(a, b []float64) []float64 {
 	// as we expect for Convolve.
 	res := make([]float64, len(a), len(a)) // we can reuse this for the output
 	off := len(b) / 2
-	for i := 0; i < off; i++ {
+	for i := 0; i < len(b)-off; i++ {
 		res[i] = b[off+i]
-		res[off+i] = b[off-i]
+	}
+	for i := 1; i <= off; i++ {
+		res[len(b)-i] = b[off-i]
 	}
 	// Compute Fourier transforms
 	fft := fourier.NewFFT(len(a))
@@ -57,6 +57,20 @@ func TestConvolve(t *testing.T) {
 	}
 }
 
+// An asymmetric response distinguishes the
+// convolution from the correlation.
+func TestConvolveAsymmetric(t *testing.T) {
+	a := []float64{1, 2, 3, 0, 0}
+	b := []float64{0, 1, 0.5} // b(0) = 1, b(1) = 0.5
+	want := []float64{1, 2.5, 4, 1.5, 0}
+	c := Convolve(a, b)
+	for i := range want {
+		if math.Abs(c[i]-want[i]) > 1e-12 {
+			t.Fatal(fmt.Sprintf("convolution is incorrect, have %v, want %v", c, want))
+		}
+	}
+}
+
 // Test FFTConvolve function
 func TestFFTConvolve(t *testing.T) {
 	xs, gs, hs, step := makeSeries()
@@ -72,3 +86,43 @@ func TestFFTConvolve(t *testing.T) {
 		}
 	}
 }
+
+// A shifted impulse reproduces the response,
+// which distinguishes the convolution from the
+// correlation for asymmetric responses.
+func TestConvolveImpulse(t *testing.T) {
+	a := make([]float64, 16)
+	b := make([]float64, 16)
+	a[5] = 1
+	b[7], b[8], b[9] = 1, 2, 3
+	for name, convolve := range map[string]func(a, b []float64) []float64{
+		"Convolve":    Convolve,
+		"FFTConvolve": FFTConvolve,
+	} {
+		c := convolve(a, b)
+		for i := range c {
+			want := 0.0
+			if 4 <= i && i <= 6 {
+				want = b[i+3]
+			}
+			if math.Abs(c[i]-want) > 1e-12 {
+				t.Fatal(fmt.Sprintf("%v is incorrect at %v, have %v, want %v", name, i, c[i], want))
+			}
+		}
+	}
+}
+
+// FFTConvolve wraps around, where Convolve
+// zero pads.
+func TestFFTConvolveWrap(t *testing.T) {
+	a := make([]float64, 8)
+	b := make([]float64, 8)
+	a[0] = 1
+	b[3] = 1 // b(-1)
+	if c := Convolve(a, b); c[7] != 0 {
+		t.Error(fmt.Sprintf("Convolve should zero pad, have %v", c))
+	}
+	if c := FFTConvolve(a, b); math.Abs(c[7]-1) > 1e-12 {
+		t.Error(fmt.Sprintf("FFTConvolve should wrap around, have %v", c))
+	}
+}
@@ -2,8 +2,11 @@ package util
 
 import (
 	"fmt"
+	"image/color"
+	"math"
 	"strings"
 
+	"github.com/dyedgreen/comp-phys/pkg/casino"
 	"github.com/dyedgreen/comp-phys/pkg/interpolate"
 
 	"gonum.org/v1/gonum/mat"
@@ -45,6 +48,76 @@ func RangeToPlotter(r interpolate.Range) plot.Plotter {
 	return pltr
 }
 
+// HistogramToPlotter wraps a histogram in a gonum plotter.Histogram.
+// If density is true, the bins show the density instead of the
+// count, which allows to overlay a probability density without
+// scaling it. The Width of the plotter, which gonum uses to
+// normalize, is only set if all bins have the same width.
+func HistogramToPlotter(h *casino.Histogram, density bool) *plotter.Histogram {
+	edges := h.Edges()
+	bins := make([]plotter.HistogramBin, h.Bins())
+	uniform := true
+	for i := range bins {
+		bins[i] = plotter.HistogramBin{Min: edges[i], Max: edges[i+1]}
+		if density {
+			bins[i].Weight, _ = h.Density(i)
+		} else {
+			bins[i].Weight, _ = h.Count(i)
+		}
+		width, first := edges[i+1]-edges[i], edges[1]-edges[0]
+		if math.Abs(width-first) > 1e-9*math.Abs(first) {
+			uniform = false
+		}
+	}
+	hist := &plotter.Histogram{
+		Bins:      bins,
+		FillColor: color.Gray{128},
+		LineStyle: plotter.DefaultLineStyle,
+	}
+	if len(bins) > 0 && uniform {
+		hist.Width = edges[1] - edges[0]
+	}
+	return hist
+}
+
+// Points of a histogram at the bin centers,
+// implementing plotter.XYer and plotter.YErrorer
+type histogramPoints struct {
+	hist    *casino.Histogram
+	edges   []float64
+	density bool
+}
+
+func (p histogramPoints) Len() int {
+	return p.hist.Bins()
+}
+
+func (p histogramPoints) XY(i int) (float64, float64) {
+	x := (p.edges[i] + p.edges[i+1]) / 2
+	if p.density {
+		y, _ := p.hist.Density(i)
+		return x, y
+	}
+	y, _ := p.hist.Count(i)
+	return x, y
+}
+
+func (p histogramPoints) YError(i int) (float64, float64) {
+	var err float64
+	if p.density {
+		_, err = p.hist.Density(i)
+	} else {
+		_, err = p.hist.Count(i)
+	}
+	return err, err
+}
+
+// HistogramToErrorBars returns the Poisson error bars of a histogram
+// at the bin centers, see HistogramToPlotter.
+func HistogramToErrorBars(h *casino.Histogram, density bool) (*plotter.YErrorBars, error) {
+	return plotter.NewYErrorBars(histogramPoints{h, h.Edges(), density})
+}
+
 // MatrixToLaTeX takes a matrix and an element-wise format string
 // and returns LaTeX code for displaying the matrix as a string.
 func MatrixToLaTeX(m mat.Matrix, format string) string {
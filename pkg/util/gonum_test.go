@@ -0,0 +1,43 @@
+package util
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/dyedgreen/comp-phys/pkg/casino"
+)
+
+func TestHistogramToPlotter(t *testing.T) {
+	uniform, _ := casino.NewHistogram(0, 1, 4)
+	edges, _ := casino.NewHistogramEdges([]float64{0, 0.1, 0.5, 1})
+	for _, x := range []float64{0.05, 0.3, 0.3, 0.7, 0.9, 0.9} {
+		uniform.Add(x)
+		edges.Add(x)
+	}
+
+	for _, h := range []*casino.Histogram{uniform, edges} {
+		bars, err := HistogramToErrorBars(h, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		plot := HistogramToPlotter(h, true)
+		if len(plot.Bins) != h.Bins() || len(bars.XYs) != h.Bins() {
+			t.Error(fmt.Sprintf("plot data should have %v bins", h.Bins()))
+			continue
+		}
+		for i := range plot.Bins {
+			d, err := h.Density(i)
+			if plot.Bins[i].Weight != d || bars.XYs[i].Y != d || bars.YErrors[i].Low != err {
+				t.Error(fmt.Sprintf("plotted density %v should be %v", plot.Bins[i].Weight, d))
+			}
+		}
+	}
+
+	// Width is only meaningful for uniform bins
+	if w := HistogramToPlotter(uniform, false).Width; w != 0.25 {
+		t.Error(fmt.Sprintf("uniform width %v should be 0.25", w))
+	}
+	if w := HistogramToPlotter(edges, false).Width; w != 0 {
+		t.Error(fmt.Sprintf("non-uniform width %v should not be set", w))
+	}
+}
@@ -44,28 +44,26 @@ func genData() {
 	// Monte Carlo Integration
 	accs := []float64{1e-3, 1e-4, 1e-5, 1e-6}
 
-	montFlat := quad.NewUniformMonteCarloIntegral(128, 128, casino.Noise(64))
+	montFlat := quad.NewUniformMonteCarloIntegral(128, 128, casino.Noise(64), nil)
 	dist, err := casino.NewLinearDist(A, B, -0.48, 0.98)
 	if err != nil {
 		panic(err)
 	}
-	montSlanted := quad.NewMonteCarloIntegral(dist, 128, 128, casino.Noise(64))
+	montSlanted := quad.NewMonteCarloIntegral(dist, 128, 128, casino.Noise(64), nil)
 
 	fmt.Println("\n-- Monte Carlo Results (Flat) --\n")
 	for _, acc := range accs {
 		montFlat.Accuracy(&acc)
 
-		start := time.Now()
 		P, err := quad.Integrate(wave_fn_2, A, B, montFlat)
-		elapsed := time.Now().Sub(start)
+		stats := montFlat.Stats()
 
 		fmt.Printf("For accuracy %v:\n        P = %v\n", acc, P)
 
 		fmt.Println("Statistics:")
-		fmt.Println(montFlat.Stats())
+		fmt.Println(stats)
 		fmt.Printf("Time elapsed: %v (%v nanosecond/sample)\n",
-			elapsed,
-			float64(elapsed.Nanoseconds())/float64(montFlat.Stats().Steps))
+			stats.Time, 1e9/stats.Rate)
 
 		if err != nil {
 			break
@@ -76,17 +74,15 @@ func genData() {
 	for _, acc := range accs {
 		montSlanted.Accuracy(&acc)
 
-		start := time.Now()
 		P, err := quad.Integrate(wave_fn_2, A, B, montSlanted)
-		elapsed := time.Now().Sub(start)
+		stats := montSlanted.Stats()
 
 		fmt.Printf("For accuracy %v:\n        P = %v\n", acc, P)
 
 		fmt.Println("Statistics:")
-		fmt.Println(montSlanted.Stats())
+		fmt.Println(stats)
 		fmt.Printf("Time elapsed: %v (%v nanosecond/sample)\n",
-			elapsed,
-			float64(elapsed.Nanoseconds())/float64(montSlanted.Stats().Steps))
+			stats.Time, 1e9/stats.Rate)
 
 		if err != nil {
 			break
@@ -110,11 +106,12 @@ func genData() {
 	}
 
 	fmt.Println("\n-- Monte Carlo Results (APIS) --\n")
-	start := time.Now()
-	P, Z := apis.Estimate()
-	elapsed := time.Now().Sub(start)
-	fmt.Printf("        P = %v\n        Z = %v\n accuracy = %v\n", P, Z, math.Abs(Z-1))
+	P, Z, err := apis.Estimate()
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("        P = %v (+/- %v)\n        Z = %v (+/- %v)\n accuracy = %v\n",
+		P.Value, P.StdErr, Z.Value, Z.StdErr, math.Abs(Z.Value-1))
 	fmt.Printf("Time elapsed: %v (%v nanosecond/sample)\n",
-		elapsed,
-		float64(elapsed.Nanoseconds())/606208)
+		P.Time, 1e9/P.Rate)
 }
@@ -67,7 +67,9 @@ func plotAcc(title, file string, n, vals, accs []float64, theory func(float64) f
 	}
 }
 
-func plotForScheme(title, file string, scheme quad.Integral, theory func(float64) float64) {
+// The theoretical bounds are fitted from the observed
+// convergence of the scheme.
+func plotForScheme(title, file string, scheme quad.Integral) {
 	const points = 25
 	maxSteps := scheme.Steps(nil)
 
@@ -89,7 +91,17 @@ func plotForScheme(title, file string, scheme quad.Integral, theory func(float64
 		accs = append(accs, stats.Accuracy)
 	}
 
-	plotAcc(title, file, n, vals, accs, theory)
+	errs := make([]float64, len(vals))
+	for i := range vals {
+		errs[i] = math.Abs(vals[i] - exactP)
+	}
+	order, err := quad.FitOrder(n, errs, 0.95)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("%v: %v\n", title, order)
+
+	plotAcc(title, file, n, vals, accs, order.Theory)
 }
 
 // Generate the nice graphs for the project report, this is slow ...
@@ -110,31 +122,21 @@ func genGraphs() {
 	simp.Accuracy(&eps)
 	simp.Steps(&simpMax)
 
-	plotForScheme("Accuracy Trapezoidal Method", "trap-accuracy", trap, func(n float64) float64 {
-		h := (B - A) / n
-		return 1e-2 * h * h // O(h^2)
-	})
-	plotForScheme("Accuracy Simpson's Method", "simp-accuracy", simp, func(n float64) float64 {
-		h := (B - A) / n
-		return 4e-2 * h * h * h * h // O(h^4)
-	})
+	plotForScheme("Accuracy Trapezoidal Method", "trap-accuracy", trap)
+	plotForScheme("Accuracy Simpson's Method", "simp-accuracy", simp)
 
 	// Monte Carlo Methods (this will take a few seconds to run ...)
 	fmt.Println("Plots for Monte-Carlo methods ...")
 
-	montFlat := quad.NewUniformMonteCarloIntegral(128, 128, casino.Noise(64))
+	montFlat := quad.NewUniformMonteCarloIntegral(128, 128, casino.Noise(64), nil)
 	dist, err := casino.NewLinearDist(A, 2, -0.48, 0.98)
 	if err != nil {
 		panic(err)
 	}
-	montSlanted := quad.NewMonteCarloIntegral(dist, 128, 128, casino.Noise(64))
+	montSlanted := quad.NewMonteCarloIntegral(dist, 128, 128, casino.Noise(64), nil)
 	montFlat.Accuracy(&eps)
 	montSlanted.Accuracy(&eps)
 
-	plotForScheme("Accuracy Uniform Importance Sampling", "mont-flat-accuracy", montFlat, func(n float64) float64 {
-		return 0.8 / math.Sqrt(n) // sqrt(var / n)
-	})
-	plotForScheme("Accuracy Slanted Importance Sampling", "mont-slanted-accuracy", montSlanted, func(n float64) float64 {
-		return 0.3 / math.Sqrt(n) // sqrt(var / n)
-	})
+	plotForScheme("Accuracy Uniform Importance Sampling", "mont-flat-accuracy", montFlat)
+	plotForScheme("Accuracy Slanted Importance Sampling", "mont-slanted-accuracy", montSlanted)
 }
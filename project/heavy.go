@@ -19,7 +19,7 @@ func genHeavyData() {
 	if err != nil {
 		panic(err)
 	}
-	mont := quad.NewMonteCarloIntegral(dist, 128, 128, casino.Noise(64))
+	mont := quad.NewMonteCarloIntegral(dist, 128, 128, casino.Noise(64), nil)
 	mont.Accuracy(&eps)
 	mont.Steps(&steps)
 
@@ -14,6 +14,10 @@ func wave_fn_2(z float64) float64 {
 	return math.Exp(-z*z) / math.SqrtPi
 }
 
+// Analytic value of the integral of
+// wave_fn_2 over [A, B]
+var exactP = (math.Erf(B) - math.Erf(A)) / 2
+
 func main() {
 	graph := flag.Bool("graph", false, "generate graphs")
 	data := flag.Bool("data", false, "print data")
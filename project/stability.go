@@ -21,7 +21,7 @@ func genStabilityGraph() {
 	const points = 100
 	const maxSteps = 5000000
 
-	mont := quad.NewUniformMonteCarloIntegral(8, 8, casino.Noise(64))
+	mont := quad.NewUniformMonteCarloIntegral(8, 8, casino.Noise(64), nil)
 
 	// this will set to machine precision
 	eps := 0.0